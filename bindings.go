@@ -0,0 +1,112 @@
+package servicehub
+
+import (
+	"fmt"
+	"sort"
+)
+
+// parseBindings parse the bindings config, which maps service references to the target services,
+// such as {storage: "storage@s3"}.
+func parseBindings(val interface{}) (map[string]string, error) {
+	if val == nil {
+		return nil, nil
+	}
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("bindings must be a map, but got %T", val)
+	}
+	bindings := make(map[string]string, len(m))
+	for service, target := range m {
+		t, ok := target.(string)
+		if !ok || len(t) <= 0 {
+			return nil, fmt.Errorf("target of binding %q must be a service name, but got %v", service, target)
+		}
+		bindings[service] = t
+	}
+	return bindings, nil
+}
+
+// binding return the service which the reference of caller is bound to,
+// bindings of caller override the top-level bindings.
+func (h *Hub) binding(caller, callerLabel, service string) string {
+	for _, pc := range h.providersMap[caller] {
+		if pc.label == callerLabel {
+			if target, ok := pc.bindings[service]; ok {
+				return target
+			}
+			break
+		}
+	}
+	if target, ok := h.bindings[service]; ok {
+		return target
+	}
+	return service
+}
+
+// bindDependency return the DependencyContext of the bound service.
+func (h *Hub) bindDependency(dc DependencyContext) DependencyContext {
+	d, ok := dc.(*dependencyContext)
+	if !ok || d.bound || len(d.key) <= 0 {
+		return dc
+	}
+	target := h.binding(d.caller, d.callerLabel, d.key)
+	if target == d.key {
+		bdc := *d
+		bdc.bound = true
+		return &bdc
+	}
+	bdc := newDependencyContext(target, d.caller, d.callerLabel, d.typ, d.tags)
+	bdc.scope, bdc.hub, bdc.bound = d.scope, d.hub, true
+	return bdc
+}
+
+// checkBindings check the targets of bindings exist and are not bound again.
+func (h *Hub) checkBindings(services map[string][]*providerContext) error {
+	check := func(owner string, bindings map[string]string) error {
+		keys := make([]string, 0, len(bindings))
+		for service := range bindings {
+			keys = append(keys, service)
+		}
+		sort.Strings(keys)
+		for _, service := range keys {
+			target := bindings[service]
+			if len(findServiceProviders(services, target)) <= 0 {
+				return fmt.Errorf("%s binds %s to %s, but it not found", owner, service, target)
+			}
+			if next, ok := bindings[target]; ok && target != service {
+				return fmt.Errorf("%s binds %s to %s, which conflicts with binding %s to %s", owner, service, target, target, next)
+			}
+		}
+		return nil
+	}
+	if err := check("bindings", h.bindings); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(h.providersMap))
+	for name := range h.providersMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, pc := range h.providersMap[name] {
+			if err := check("provider "+pc.key, pc.bindings); err != nil {
+				return err
+			}
+			for service, target := range pc.bindings {
+				if next, ok := h.bindings[target]; ok && target != service {
+					return fmt.Errorf("provider %s binds %s to %s, which conflicts with binding %s to %s in bindings", pc.key, service, target, target, next)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
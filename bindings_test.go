@@ -0,0 +1,123 @@
+package servicehub
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestHub_Bindings(t *testing.T) {
+	type consumer struct {
+		Store testStore `autowired:"store"`
+	}
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		consumer string // store name got by consumer
+		other    string // store name got by other
+		wantErr  bool
+	}{
+		{
+			name:    "ambiguous without bindings",
+			config:  map[string]interface{}{},
+			wantErr: true,
+		},
+		{
+			name: "top-level bindings",
+			config: map[string]interface{}{
+				"bindings": map[string]interface{}{"store": "store@s3"},
+			},
+			consumer: "s3",
+			other:    "s3",
+		},
+		{
+			name: "provider bindings",
+			config: map[string]interface{}{
+				"bindings": map[string]interface{}{"store": "store@s3"},
+				"consumer": map[string]interface{}{
+					"_bindings": map[string]interface{}{"store": "redis-store"},
+				},
+			},
+			consumer: "redis",
+			other:    "s3",
+		},
+		{
+			name: "unknown target",
+			config: map[string]interface{}{
+				"bindings": map[string]interface{}{"store": "store@not-exist"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "conflict",
+			config: map[string]interface{}{
+				"bindings": map[string]interface{}{"redis-store": "store@s3"},
+				"consumer": map[string]interface{}{
+					"_bindings": map[string]interface{}{"store": "redis-store"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid",
+			config: map[string]interface{}{
+				"bindings": map[string]interface{}{"store": 1},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register("memory-store", &Spec{
+				Services: []string{"store"},
+				Creator:  func() Provider { return &testStoreImpl{"memory"} },
+			})
+			r.Register("s3-store", &Spec{
+				Services: []string{"store"},
+				Creator:  func() Provider { return &testStoreImpl{"s3"} },
+			})
+			r.Register("redis-store", &Spec{
+				Services: []string{"redis-store"},
+				Creator:  func() Provider { return &testStoreImpl{"redis"} },
+			})
+			c, o := &consumer{}, &consumer{}
+			r.Register("consumer", &Spec{Creator: func() Provider { return c }})
+			r.Register("other", &Spec{Creator: func() Provider { return o }})
+			cfg := map[string]interface{}{
+				"memory-store": nil,
+				"s3-store@s3":  nil,
+				"redis-store":  nil,
+				"consumer":     nil,
+				"other":        nil,
+			}
+			for k, v := range tt.config {
+				cfg[k] = v
+			}
+			hub := New(WithRegistry(r))
+			err := hub.Init(cfg, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Hub.Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if c.Store.Name() != tt.consumer {
+				t.Errorf("consumer got store %q, want %q", c.Store.Name(), tt.consumer)
+			}
+			if o.Store.Name() != tt.other {
+				t.Errorf("other got store %q, want %q", o.Store.Name(), tt.other)
+			}
+			for _, node := range hub.DependencyGraph() {
+				if node.Name != "consumer" {
+					continue
+				}
+				for _, edge := range node.Edges {
+					if edge.Field == "Store" && hub.Provider(edge.To).(testStore).Name() != tt.consumer {
+						t.Errorf("consumer depends on %s, want the bound provider", edge.To)
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,16 +1,22 @@
 package servicehub
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/recallsong/go-utils/config"
 )
 
-func (h *Hub) loadConfig(file string, cfg map[string]interface{}) (map[string]interface{}, error) {
-	err := config.LoadToMap(file, cfg)
+func (h *Hub) loadConfig(file string, cfg map[string]interface{}, sources map[string]string) (map[string]interface{}, error) {
+	layer := make(map[string]interface{})
+	err := readConfigFile(file, layer)
 	if err != nil {
 		if os.IsNotExist(err) {
 			if len(cfg) <= 0 {
@@ -24,12 +30,67 @@ func (h *Hub) loadConfig(file string, cfg map[string]interface{}) (map[string]in
 		return nil, err
 	}
 	h.logger.Debugf("using config file: %s", file)
+	mergeConfig(cfg, layer, file, sources)
+	return cfg, nil
+}
+
+// readConfigFile read file into cfg by its extension, the environment variables are not replaced here,
+// but by interpolateConfig on the whole config.
+func readConfigFile(file string, cfg map[string]interface{}) error {
+	ext := filepath.Ext(file)
+	if len(ext) <= 0 {
+		return fmt.Errorf("%s unknown file extension", file)
+	}
+	byts, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return unmarshalConfig(config.TrimBOM(byts), ext[1:], cfg)
+}
+
+// unmarshalConfig parse byts of format into cfg, the keys of cfg are lowercased except the environment variables in them.
+func unmarshalConfig(byts []byte, format string, cfg map[string]interface{}) error {
+	err := config.UnmarshalToMap(bytes.NewReader(byts), format, cfg)
+	if err != nil {
+		return err
+	}
+	restoreEnvKeys(cfg, byts)
+	return nil
+}
+
+// loadConfigFiles load files in order and merge them into cfg, the file of profile is loaded after each file,
+// such as app.prod.yaml after app.yaml.
+func (h *Hub) loadConfigFiles(files []string, profile string, cfg map[string]interface{}, sources map[string]string) (map[string]interface{}, error) {
+	var err error
+	for _, file := range files {
+		cfg, err = h.loadConfig(file, cfg, sources)
+		if err != nil {
+			return nil, err
+		}
+		if len(profile) <= 0 {
+			continue
+		}
+		pfile := profileFile(file, profile)
+		if _, err := os.Stat(pfile); err != nil {
+			h.logger.Debugf("config file %s of profile %s not exist", pfile, profile)
+			continue
+		}
+		cfg, err = h.loadConfig(pfile, cfg, sources)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return cfg, nil
 }
 
 func (h *Hub) loadProviders(config map[string]interface{}) error {
 	h.providersMap = map[string][]*providerContext{}
-	err := h.doLoadProviders(config, "providers")
+	bindings, err := parseBindings(config["bindings"])
+	if err != nil {
+		return fmt.Errorf("invalid bindings: %s", err)
+	}
+	h.bindings = bindings
+	err = h.doLoadProviders(config, "providers", "bindings")
 	if err != nil {
 		return err
 	}
@@ -48,13 +109,13 @@ func (h *Hub) loadProviders(config map[string]interface{}) error {
 				}
 			}
 		case map[string]interface{}:
-			err = h.doLoadProviders(providers, "")
+			err = h.doLoadProviders(providers)
 			if err != nil {
 				return err
 			}
 		}
 	}
-	for name := range globalProviders {
+	for _, name := range h.registry.GlobalNames() {
 		if _, ok := config[name]; ok {
 			h.logger.Warnf("provider %q conflict with global provider", name)
 			continue
@@ -67,10 +128,10 @@ func (h *Hub) loadProviders(config map[string]interface{}) error {
 	return nil
 }
 
-func (h *Hub) doLoadProviders(config map[string]interface{}, filter string) error {
+func (h *Hub) doLoadProviders(config map[string]interface{}, filter ...string) error {
 	for key, cfg := range config {
-		if key == filter {
-			continue
+		if containsString(filter, key) || strings.HasPrefix(key, "_") {
+			continue // sections starting with _ are shared config, such as injected by config:"$._shared"
 		}
 		err := h.addProvider(key, cfg)
 		if err != nil {
@@ -82,6 +143,9 @@ func (h *Hub) doLoadProviders(config map[string]interface{}, filter string) erro
 
 func (h *Hub) addProvider(key string, cfg interface{}) error {
 	name, label := key, ""
+	var exitTimeout time.Duration
+	restart := defaultRestartOptions()
+	var bindings map[string]string
 	idx := strings.Index(key, "@")
 	if idx > 0 {
 		name = key[0:idx]
@@ -95,8 +159,41 @@ func (h *Hub) addProvider(key string, cfg interface{}) error {
 				}
 			}
 			if val, ok := v["_enable"]; ok {
-				if enable, ok := val.(bool); ok && !enable {
-					return nil
+				switch enable := val.(type) {
+				case bool:
+					if !enable {
+						return nil
+					}
+				case string:
+					// such as _enable: ${ENABLE_PROVIDER:-true}
+					ok, err := strconv.ParseBool(enable)
+					if err != nil {
+						return fmt.Errorf("invalid _enable of provider %s: %s", key, err)
+					}
+					if !ok {
+						return nil
+					}
+				}
+			}
+			if val, ok := v["_exit_timeout"]; ok {
+				timeout, err := time.ParseDuration(fmt.Sprint(val))
+				if err != nil {
+					return fmt.Errorf("invalid _exit_timeout of provider %s: %s", key, err)
+				}
+				exitTimeout = timeout
+			}
+			if val, ok := v["_restart"]; ok {
+				opts, err := parseRestartOptions(val)
+				if err != nil {
+					return fmt.Errorf("invalid _restart of provider %s: %s", key, err)
+				}
+				restart = opts
+			}
+			if val, ok := v["_bindings"]; ok {
+				var err error
+				bindings, err = parseBindings(val)
+				if err != nil {
+					return fmt.Errorf("invalid _bindings of provider %s: %s", key, err)
 				}
 			}
 		}
@@ -104,23 +201,51 @@ func (h *Hub) addProvider(key string, cfg interface{}) error {
 	if len(name) <= 0 {
 		return fmt.Errorf("provider name must not be empty")
 	}
-	define, ok := serviceProviders[name]
+	define, ok := h.registry.Get(name)
 	if !ok {
-		define, ok = globalProviders[name]
-		if !ok {
-			return fmt.Errorf("provider %s not exist", name)
+		return fmt.Errorf("provider %s not exist", name)
+	}
+	var provider Provider
+	var ctor *constructor
+	if pc, ok := define.(ProviderConstructor); ok {
+		fn, services := pc.Constructor()
+		if fn != nil {
+			var err error
+			ctor, err = newConstructor(fn, services)
+			if err != nil {
+				return fmt.Errorf("invalid constructor of provider %s: %s", name, err)
+			}
+		}
+	}
+	var lifetime Lifetime
+	if pl, ok := define.(ProviderLifetime); ok {
+		lifetime = pl.Lifetime()
+		if !lifetime.valid() {
+			return fmt.Errorf("invalid lifetime %q of provider %s", lifetime, name)
+		}
+	}
+	if ctor == nil {
+		creator := define.Creator()
+		if creator == nil {
+			return fmt.Errorf("provider %s has no Creator or Constructor", name)
 		}
+		provider = creator()
 	}
-	provider := define.Creator()()
 	pctx := &providerContext{
-		Context:  h.ctx,
-		hub:      h,
-		key:      key,
-		label:    label,
-		name:     name,
-		cfg:      cfg,
-		provider: provider,
-		define:   define,
+		Context:     h.ctx,
+		hub:         h,
+		key:         key,
+		label:       label,
+		name:        name,
+		cfg:         cfg,
+		rawCfg:      cfg,
+		provider:    provider,
+		define:      define,
+		exitTimeout: exitTimeout,
+		restart:     restart,
+		constructor: ctor,
+		lifetime:    lifetime,
+		bindings:    bindings,
 	}
 	if provider != nil {
 		value := reflect.ValueOf(provider)
@@ -0,0 +1,96 @@
+package servicehub
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// mergeConfig deep merge src into dst, and record the source of each value in sources.
+// Maps are merged recursively, other values such as lists and scalars in src replace the values in dst,
+// so a later layer can override a single key of provider config without repeating the others,
+// but it must repeat the whole list to change a list. Nil values in src, such as a bare "mysql:"
+// which enables a provider, do not change the existing values in dst.
+func mergeConfig(dst, src map[string]interface{}, source string, sources map[string]string) {
+	mergeConfigPath(dst, src, source, sources, "")
+}
+
+func mergeConfigPath(dst, src map[string]interface{}, source string, sources map[string]string, prefix string) {
+	for key, val := range src {
+		path := prefix + key
+		if _, exist := dst[key]; exist && val == nil {
+			continue
+		}
+		if sm, ok := val.(map[string]interface{}); ok {
+			if dm, ok := dst[key].(map[string]interface{}); ok {
+				mergeConfigPath(dm, sm, source, sources, path+".")
+				continue
+			}
+			dm := make(map[string]interface{}, len(sm))
+			removeSources(sources, path)
+			mergeConfigPath(dm, sm, source, sources, path+".")
+			if len(sm) <= 0 && sources != nil {
+				sources[path] = source
+			}
+			dst[key] = dm
+			continue
+		}
+		removeSources(sources, path)
+		if sources != nil {
+			sources[path] = source
+		}
+		dst[key] = val
+	}
+}
+
+// removeSources remove the sources of path and its children, they are replaced by a later layer.
+func removeSources(sources map[string]string, path string) {
+	for key := range sources {
+		if key == path || strings.HasPrefix(key, path+".") {
+			delete(sources, key)
+		}
+	}
+}
+
+// profileFile return the file of profile which overlays file, such as app.prod.yaml for app.yaml.
+func profileFile(file, profile string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + profile + ext
+}
+
+// ConfigSource return the source of config value at path, such as "provider.addr",
+// the source is the config file or "content" of RunOptions. An empty string is returned if not found,
+// or the value is a map merged from many layers.
+func (h *Hub) ConfigSource(path string) string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	for len(path) > 0 {
+		if source, ok := h.configSources[path]; ok {
+			return source
+		}
+		idx := strings.LastIndex(path, ".")
+		if idx < 0 {
+			break
+		}
+		path = path[:idx]
+	}
+	return ""
+}
+
+// ConfigSources return the paths of config values and their sources, sorted by path.
+func (h *Hub) ConfigSources() []*ConfigValueSource {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	list := make([]*ConfigValueSource, 0, len(h.configSources))
+	for path, source := range h.configSources {
+		list = append(list, &ConfigValueSource{Path: path, Source: source})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	return list
+}
+
+// ConfigValueSource describes which layer a config value came from.
+type ConfigValueSource struct {
+	Path   string `json:"path"`
+	Source string `json:"source"`
+}
@@ -0,0 +1,87 @@
+package servicehub
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/recallsong/servicehub/logs/logrusx"
+)
+
+func TestHub_loadConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.yaml": `
+http-server:
+    addr: ":8080"
+    routes: ["/a", "/b"]
+    tls:
+        enable: false
+mysql:
+    host: localhost
+`,
+		"base.prod.yaml": `
+http-server:
+    tls:
+        enable: true
+`,
+		"local.yaml": `
+http-server:
+    routes: ["/c"]
+mysql:
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base, local := filepath.Join(dir, "base.yaml"), filepath.Join(dir, "local.yaml")
+	prod := filepath.Join(dir, "base.prod.yaml")
+
+	hub := New(WithLogger(logrusx.New()))
+	hub.configSources = make(map[string]string)
+	cfg, err := hub.loadConfigFiles([]string{base, local, filepath.Join(dir, "not-exist.yaml")}, "prod", map[string]interface{}{}, hub.configSources)
+	if err != nil {
+		t.Fatalf("Hub.loadConfigFiles() = %v, want nil", err)
+	}
+	want := map[string]interface{}{
+		"http-server": map[string]interface{}{
+			"addr":   ":8080",
+			"routes": []interface{}{"/c"},
+			"tls":    map[string]interface{}{"enable": true},
+		},
+		"mysql": map[string]interface{}{"host": "localhost"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Hub.loadConfigFiles() = %v, want %v", cfg, want)
+	}
+	sources := map[string]string{
+		"http-server.addr":       base,
+		"http-server.routes":     local,
+		"http-server.tls.enable": prod,
+		"mysql":                  "",
+		"mysql.host":             base,
+		"not-exist":              "",
+	}
+	for path, source := range sources {
+		if got := hub.ConfigSource(path); got != source {
+			t.Errorf("Hub.ConfigSource(%q) = %q, want %q", path, got, source)
+		}
+	}
+}
+
+func Test_mergeConfig(t *testing.T) {
+	dst := map[string]interface{}{"mysql": map[string]interface{}{"host": "localhost"}}
+	sources := map[string]string{"mysql.host": "base"}
+	mergeConfig(dst, map[string]interface{}{"mysql": nil, "redis": nil}, "local", sources)
+	want := map[string]interface{}{"mysql": map[string]interface{}{"host": "localhost"}, "redis": nil}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("mergeConfig() = %v, want %v", dst, want)
+	}
+	wantSources := map[string]string{"mysql.host": "base", "redis": "local"}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("sources = %v, want %v", sources, wantSources)
+	}
+}
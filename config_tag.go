@@ -0,0 +1,90 @@
+package servicehub
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/recallsong/go-utils/config"
+	"github.com/recallsong/unmarshal"
+)
+
+// rootConfigPrefix is the prefix of config tag to lookup the section in top-level config
+const rootConfigPrefix = "$."
+
+// configSection return the section of config by path, such as "db.master" in the config of provider,
+// or "$._shared.db" in the top-level config.
+func (c *providerContext) configSection(path string) (interface{}, bool) {
+	return lookupConfigSection(c.rawCfg, c.hub.config, path)
+}
+
+// lookupConfigSection return the section of path in raw config of provider, or in root config if path starts with $.
+func lookupConfigSection(raw interface{}, root map[string]interface{}, path string) (interface{}, bool) {
+	section := raw
+	if strings.HasPrefix(path, rootConfigPrefix) {
+		section = root
+		path = path[len(rootConfigPrefix):]
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch m := section.(type) {
+		case map[string]interface{}:
+			val, ok := m[key]
+			if !ok {
+				return nil, false
+			}
+			section = val
+		case map[interface{}]interface{}:
+			val, ok := m[key]
+			if !ok {
+				return nil, false
+			}
+			section = val
+		default:
+			return nil, false
+		}
+	}
+	return section, true
+}
+
+// injectConfig decode the config section of path into field,
+// the default and env tags of struct are bound before and after the section is decoded.
+func (c *providerContext) injectConfig(field reflect.Value, path string) error {
+	val, err := c.decodeConfigSection(field.Type(), path)
+	if err != nil {
+		return err
+	}
+	field.Set(val)
+	return nil
+}
+
+// decodeConfigSection return a value of typ decoded from the config section of path.
+func (c *providerContext) decodeConfigSection(typ reflect.Type, path string) (reflect.Value, error) {
+	if len(path) <= 0 || path == rootConfigPrefix {
+		return reflect.Value{}, fmt.Errorf("invalid config path %q", path)
+	}
+	ptr := reflect.New(typ)
+	isStruct := indirectType(typ).Kind() == reflect.Struct
+	if isStruct {
+		if err := unmarshal.BindDefault(ptr.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if section, ok := c.configSection(path); ok && section != nil {
+		if err := config.ConvertData(section, ptr.Interface(), "file"); err != nil {
+			return reflect.Value{}, fmt.Errorf("failed to decode config %q: %s", path, err)
+		}
+	}
+	if isStruct {
+		if err := unmarshal.BindEnv(ptr.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return ptr.Elem(), nil
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
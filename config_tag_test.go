@@ -0,0 +1,54 @@
+package servicehub
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func TestHub_ConfigTag(t *testing.T) {
+	type dbConfig struct {
+		Host    string        `file:"host" default:"localhost"`
+		Port    int           `file:"port" default:"3306"`
+		Timeout time.Duration `file:"timeout" env:"TEST_CONFIG_TAG_TIMEOUT" default:"1s"`
+	}
+	type consumer struct {
+		Master  dbConfig  `config:"db.master"`
+		Slave   *dbConfig `config:"db.slave"`
+		Missing dbConfig  `config:"db.missing"`
+		Tags    []string  `config:"tags"`
+		Shared  string    `config:"$._shared.region"`
+	}
+	os.Setenv("TEST_CONFIG_TAG_TIMEOUT", "3s")
+	defer os.Unsetenv("TEST_CONFIG_TAG_TIMEOUT")
+	r := NewRegistry()
+	c := &consumer{}
+	r.Register("consumer", &Spec{Creator: func() Provider { return c }})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"_shared": map[string]interface{}{"region": "us-east"},
+		"consumer": map[string]interface{}{
+			"db": map[string]interface{}{
+				"master": map[string]interface{}{"host": "master", "port": 3307},
+				"slave":  map[string]interface{}{"host": "slave", "timeout": "2s"},
+			},
+			"tags": []interface{}{"a", "b"},
+		},
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	want := &consumer{
+		Master:  dbConfig{Host: "master", Port: 3307, Timeout: 3 * time.Second},
+		Slave:   &dbConfig{Host: "slave", Port: 3306, Timeout: 3 * time.Second},
+		Missing: dbConfig{Host: "localhost", Port: 3306, Timeout: 3 * time.Second},
+		Tags:    []string{"a", "b"},
+		Shared:  "us-east",
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("consumer = %+v, want %+v", c, want)
+	}
+}
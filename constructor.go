@@ -0,0 +1,131 @@
+package servicehub
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var (
+	contextType = reflect.TypeOf((*Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	hubType     = reflect.TypeOf((*Hub)(nil))
+)
+
+// constructor creates provider by calling a function with injected parameters.
+type constructor struct {
+	fn       reflect.Value
+	services []string
+}
+
+func newConstructor(fn interface{}, services []string) (*constructor, error) {
+	value := reflect.ValueOf(fn)
+	typ := value.Type()
+	if typ.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%s is not a function", typ)
+	}
+	if typ.IsVariadic() {
+		return nil, fmt.Errorf("variadic function %s is not supported", typ)
+	}
+	if typ.NumOut() < 1 || typ.NumOut() > 2 || (typ.NumOut() == 2 && typ.Out(1) != errorType) {
+		return nil, fmt.Errorf("function %s must return (Provider) or (Provider, error)", typ)
+	}
+	if len(services) > typ.NumIn() {
+		return nil, fmt.Errorf("function %s has %d parameters, but got %d services", typ, typ.NumIn(), len(services))
+	}
+	return &constructor{fn: value, services: services}, nil
+}
+
+// service return the service name of parameter i, empty means injected by type.
+func (ctor *constructor) service(i int) string {
+	if i < len(ctor.services) {
+		return ctor.services[i]
+	}
+	return ""
+}
+
+// builtin return true if the parameter is not a dependency, but the logger, context, hub or config of provider.
+func (ctor *constructor) builtin(typ, cfgType reflect.Type) bool {
+	return typ == loggerType || typ == contextType || typ == hubType || (cfgType != nil && typ == cfgType)
+}
+
+func (ctor *constructor) dependencies(c *providerContext) (list []*dependency, err error) {
+	var cfgType reflect.Type
+	if creator, ok := c.define.(ConfigCreator); ok {
+		if cfg := creator.Config(); cfg != nil {
+			cfgType = reflect.TypeOf(cfg)
+		}
+	}
+	typ := ctor.fn.Type()
+	for i, num := 0, typ.NumIn(); i < num; i++ {
+		param := fmt.Sprintf("param%d", i)
+		if service := ctor.service(i); len(service) > 0 {
+			list = append(list, &dependency{service: service, source: dependencySourceCtor, field: param})
+			continue
+		}
+		if ctor.builtin(typ.In(i), cfgType) {
+			continue
+		}
+		plist, err := c.hub.findTypeProviders(typ.In(i), true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inject parameter %d of constructor: %s", i, err)
+		}
+		if len(plist) > 0 {
+			list = append(list, &dependency{provider: plist[0].name, source: dependencySourceCtor, field: param})
+		}
+	}
+	return list, nil
+}
+
+func (ctor *constructor) construct(c *providerContext) (Provider, error) {
+	var cfgType reflect.Type
+	if c.cfg != nil {
+		cfgType = reflect.TypeOf(c.cfg)
+	}
+	typ := ctor.fn.Type()
+	args := make([]reflect.Value, typ.NumIn())
+	for i := range args {
+		ptyp := typ.In(i)
+		service := ctor.service(i)
+		if len(service) <= 0 && ctor.builtin(ptyp, cfgType) {
+			switch ptyp {
+			case loggerType:
+				args[i] = reflect.New(loggerType).Elem()
+				if logger := c.Logger(); logger != nil {
+					args[i].Set(reflect.ValueOf(logger))
+				}
+			case contextType:
+				args[i] = reflect.ValueOf(c)
+			case hubType:
+				args[i] = reflect.ValueOf(c.hub)
+			case cfgType:
+				args[i] = reflect.ValueOf(c.cfg)
+			}
+			continue
+		}
+		instance, err := c.hub.getService(newDependencyContext(
+			service,
+			c.name,
+			c.label,
+			ptyp,
+			autowiredTag,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("failed to inject parameter %d: %s", i, err)
+		}
+		if instance == nil {
+			if len(service) > 0 {
+				return nil, fmt.Errorf("not found service %q for parameter %d", service, i)
+			}
+			return nil, fmt.Errorf("not found service of type %s for parameter %d", ptyp, i)
+		}
+		if !reflect.TypeOf(instance).AssignableTo(ptyp) {
+			return nil, fmt.Errorf("service %q not implement %s for parameter %d", service, ptyp, i)
+		}
+		args[i] = reflect.ValueOf(instance)
+	}
+	out := ctor.fn.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	return out[0].Interface(), nil
+}
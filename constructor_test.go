@@ -0,0 +1,134 @@
+package servicehub
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/recallsong/servicehub/logs"
+	"github.com/spf13/pflag"
+)
+
+type testCtorDB interface {
+	Name() string
+}
+
+type testCtorDBImpl struct{ name string }
+
+func (db *testCtorDBImpl) Name() string { return db.name }
+
+type testCtorConfig struct {
+	Addr string `file:"addr" default:"localhost"`
+}
+
+type testCtorProvider struct {
+	cfg   *testCtorConfig
+	db    testCtorDB
+	cache interface{}
+	log   logs.Logger
+	ctx   Context
+	hub   *Hub
+}
+
+func TestHub_Constructor(t *testing.T) {
+	tests := []struct {
+		name        string
+		constructor interface{}
+		args        []string
+		wantHub     bool
+		wantErr     bool
+	}{
+		{
+			name: "inject",
+			constructor: func(cfg *testCtorConfig, db testCtorDB, cache interface{}, log logs.Logger, ctx Context) *testCtorProvider {
+				return &testCtorProvider{cfg: cfg, db: db, cache: cache, log: log, ctx: ctx}
+			},
+			args: []string{"", "", "test-cache"},
+		},
+		{
+			name: "inject hub",
+			constructor: func(hub *Hub, cfg *testCtorConfig, db testCtorDB, cache interface{}, log logs.Logger, ctx Context) *testCtorProvider {
+				return &testCtorProvider{hub: hub, cfg: cfg, db: db, cache: cache, log: log, ctx: ctx}
+			},
+			args:    []string{"", "", "", "test-cache"},
+			wantHub: true,
+		},
+		{
+			name: "return error",
+			constructor: func(db testCtorDB) (*testCtorProvider, error) {
+				return nil, fmt.Errorf("connection refused")
+			},
+			wantErr: true,
+		},
+		{
+			name:        "missing service",
+			constructor: func(db fmt.Stringer) *testCtorProvider { return nil },
+			wantErr:     true,
+		},
+		{
+			name:        "invalid constructor",
+			constructor: func() {},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register("test-db-provider", &Spec{
+				Types:   []reflect.Type{reflect.TypeOf((*testCtorDB)(nil)).Elem()},
+				Creator: func() Provider { return &testCtorDBImpl{"test-db"} },
+			})
+			r.Register("test-cache-provider", &Spec{
+				Services: []string{"test-cache"},
+				Creator:  func() Provider { return "cache" },
+			})
+			r.Register("test-provider", &Spec{
+				Services:        []string{"test"},
+				ConfigFunc:      func() interface{} { return &testCtorConfig{} },
+				Constructor:     tt.constructor,
+				ConstructorArgs: tt.args,
+			})
+			hub := New(WithRegistry(r))
+			err := hub.Init(map[string]interface{}{
+				"test-db-provider":    nil,
+				"test-cache-provider": nil,
+				"test-provider":       nil,
+			}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Hub.Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			p, ok := hub.Service("test").(*testCtorProvider)
+			if !ok {
+				t.Fatalf("service test is not *testCtorProvider")
+			}
+			if p.cfg == nil || p.cfg.Addr != "localhost" {
+				t.Errorf("config not injected: %v", p.cfg)
+			}
+			if p.db == nil || p.db.Name() != "test-db" {
+				t.Errorf("db not injected: %v", p.db)
+			}
+			if p.cache != "cache" {
+				t.Errorf("cache not injected: %v", p.cache)
+			}
+			if p.log == nil || p.ctx == nil || p.ctx.Key() != "test-provider" {
+				t.Errorf("logger or context not injected")
+			}
+			if tt.wantHub && p.hub != hub {
+				t.Errorf("hub not injected")
+			}
+			var deps []string
+			for _, node := range hub.DependencyGraph() {
+				if node.Name == "test-provider" {
+					deps = node.Deps
+				}
+			}
+			if !reflect.DeepEqual(deps, []string{"test-cache-provider", "test-db-provider"}) &&
+				!reflect.DeepEqual(deps, []string{"test-db-provider", "test-cache-provider"}) {
+				t.Errorf("dependencies of test-provider = %v, want test-db-provider and test-cache-provider", deps)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package servicehub
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// Decorator wrap the instance of service before it is handed to the dependent, such as adding metrics, caching or retry.
+type Decorator func(instance interface{}, dc DependencyContext) interface{}
+
+// DecoratorOption .
+type DecoratorOption func(*decorator)
+
+// WithDecoratorOrder set the order of decorator, decorators with smaller order are applied first,
+// so they are wrapped by the decorators with larger order. Decorators with the same order are applied in registration order.
+func WithDecoratorOrder(order int) DecoratorOption {
+	return func(d *decorator) {
+		d.order = order
+	}
+}
+
+// WithDecoratorDependencies declare the services used by decorator, they are initialized before the decorated providers.
+func WithDecoratorDependencies(services ...string) DecoratorOption {
+	return func(d *decorator) {
+		d.dependencies = append(d.dependencies, services...)
+	}
+}
+
+type decorator struct {
+	service      string
+	typ          reflect.Type
+	fn           Decorator
+	order        int
+	seq          int
+	dependencies []string
+}
+
+// match return true if the decorator should be applied to the lookup.
+func (d *decorator) match(dc DependencyContext) bool {
+	if len(d.service) > 0 {
+		return dc.Service() == d.service
+	}
+	return dc.Type() == d.typ
+}
+
+// decorates return true if the decorator may be applied to the instances of provider.
+func (d *decorator) decorates(c *providerContext) bool {
+	if len(d.service) > 0 {
+		if ps, ok := c.define.(ProviderServices); ok {
+			for _, service := range ps.Services() {
+				if service == d.service {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if st, ok := c.define.(ServiceTypes); ok {
+		for _, typ := range st.Types() {
+			if typ.AssignableTo(d.typ) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RegisterDecorator register a decorator for the service, it is applied when the service is looked up by name.
+func (r *Registry) RegisterDecorator(service string, fn Decorator, options ...DecoratorOption) error {
+	if len(service) <= 0 {
+		return fmt.Errorf("service of decorator must not be empty")
+	}
+	return r.addDecorator(&decorator{service: service, fn: fn}, options)
+}
+
+// RegisterTypeDecorator register a decorator for the type, it is applied when the service is looked up as typ,
+// such as a field of typ.
+func (r *Registry) RegisterTypeDecorator(typ reflect.Type, fn Decorator, options ...DecoratorOption) error {
+	if typ == nil {
+		return fmt.Errorf("type of decorator must not be nil")
+	}
+	return r.addDecorator(&decorator{typ: typ, fn: fn}, options)
+}
+
+func (r *Registry) addDecorator(d *decorator, options []DecoratorOption) error {
+	if d.fn == nil {
+		return fmt.Errorf("decorator must not be nil")
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	d.seq = len(r.decorators)
+	r.decorators = append(r.decorators, d)
+	return nil
+}
+
+// decoratorList return the decorators in the order of applying.
+func (r *Registry) decoratorList() []*decorator {
+	r.lock.RLock()
+	list := append([]*decorator(nil), r.decorators...)
+	r.lock.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].order != list[j].order {
+			return list[i].order < list[j].order
+		}
+		return list[i].seq < list[j].seq
+	})
+	return list
+}
+
+// RegisterDecorator .
+func RegisterDecorator(service string, fn Decorator, options ...DecoratorOption) {
+	err := defaultRegistry.RegisterDecorator(service, fn, options...)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+}
+
+// RegisterTypeDecorator .
+func RegisterTypeDecorator(typ reflect.Type, fn Decorator, options ...DecoratorOption) {
+	err := defaultRegistry.RegisterTypeDecorator(typ, fn, options...)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+}
+
+// decoratedKey identifies a decorated instance, decorators which match the lookup are decided by service and type,
+// and the result is kept per dependent because decorators may depend on the caller in DependencyContext.
+type decoratedKey struct {
+	pc        *providerContext
+	dependent string
+	service   string
+	typ       reflect.Type
+}
+
+// decorate apply the decorators which match the lookup to the instance of provider,
+// the decorated instance is shared by the lookups of the same dependent as long as the instance is, according to the lifetime of provider.
+func (h *Hub) decorate(pc *providerContext, instance interface{}, dc DependencyContext) interface{} {
+	if instance == nil {
+		return nil
+	}
+	var list []*decorator
+	for _, d := range h.decorators {
+		if d.decorates(pc) && d.match(dc) {
+			list = append(list, d)
+		}
+	}
+	if len(list) <= 0 {
+		return instance
+	}
+	apply := func() interface{} {
+		decorated := instance
+		for _, d := range list {
+			decorated = d.fn(decorated, dc)
+		}
+		return decorated
+	}
+	key := decoratedKey{pc: pc, dependent: dependentKey(dc), service: dc.Service(), typ: dc.Type()}
+	if _, ok := pc.provider.(DependencyProvider); ok {
+		switch pc.lifetime {
+		case LifetimeSingleton, LifetimePerDependent:
+		case LifetimeScoped:
+			if d, ok := dc.(*dependencyContext); ok && d.scope != nil {
+				return d.scope.decorated(key, apply)
+			}
+			return apply()
+		default:
+			return apply() // a new instance on every lookup
+		}
+	}
+	if decorated, ok := h.decorated.Load(key); ok {
+		return decorated
+	}
+	decorated, _ := h.decorated.LoadOrStore(key, apply())
+	return decorated
+}
+
+// decoratorDependencies return the dependencies of decorators which may be applied to the provider.
+func (c *providerContext) decoratorDependencies() (list []*dependency) {
+	for _, d := range c.hub.decorators {
+		if !d.decorates(c) {
+			continue
+		}
+		for _, service := range d.dependencies {
+			list = append(list, &dependency{service: service, source: dependencySourceDecorator})
+		}
+	}
+	return list
+}
@@ -0,0 +1,156 @@
+package servicehub
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type testDecoratedStore struct {
+	testStore
+	prefix string
+}
+
+func (s *testDecoratedStore) Name() string { return s.prefix + s.testStore.Name() }
+
+func TestHub_Decorator(t *testing.T) {
+	type consumer struct {
+		ByName testStore `autowired:"store"`
+		ByType testStore
+	}
+	var metrics interface{}
+	r := NewRegistry()
+	RegisterTypedTo[testStore](r, "memory-store", &Spec{
+		Services: []string{"store"},
+		Creator:  func() Provider { return &testStoreImpl{"memory"} },
+	})
+	r.Register("metrics-provider", &Spec{
+		Services: []string{"metrics"},
+		Creator:  func() Provider { return "metrics" },
+	})
+	c := &consumer{}
+	r.Register("consumer", &Spec{
+		Creator: func() Provider { return c },
+	})
+	r.RegisterDecorator("store", func(instance interface{}, dc DependencyContext) interface{} {
+		metrics = dc.(DependencyHub).Hub().Service("metrics")
+		return &testDecoratedStore{instance.(testStore), "metrics:"}
+	}, WithDecoratorOrder(2), WithDecoratorDependencies("metrics"))
+	r.RegisterDecorator("store", func(instance interface{}, dc DependencyContext) interface{} {
+		return &testDecoratedStore{instance.(testStore), "cache:"}
+	}, WithDecoratorOrder(1))
+	r.RegisterTypeDecorator(TypeOf[testStore](), func(instance interface{}, dc DependencyContext) interface{} {
+		return &testDecoratedStore{instance.(testStore), "retry:"}
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"memory-store":     nil,
+		"metrics-provider": nil,
+		"consumer":         nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	if got := c.ByName.Name(); got != "metrics:cache:retry:memory" {
+		t.Errorf("consumer.ByName.Name() = %q, want decorated by all decorators in order", got)
+	}
+	if got := c.ByType.Name(); got != "retry:memory" {
+		t.Errorf("consumer.ByType.Name() = %q, want decorated by type decorator only", got)
+	}
+	if got := hub.Service("store").(testStore).Name(); got != "metrics:cache:memory" {
+		t.Errorf("Hub.Service().Name() = %q, want decorated by service decorators", got)
+	}
+	if hub.Service("store") != hub.Service("store") {
+		t.Errorf("Hub.Service() got different decorated instances of the same provider")
+	}
+	if metrics != "metrics" {
+		t.Errorf("dependency of decorator = %v, want metrics", metrics)
+	}
+	for _, node := range hub.DependencyGraph() {
+		if node.Name == "memory-store" && !reflect.DeepEqual(node.Deps, []string{"metrics-provider"}) {
+			t.Errorf("dependencies of memory-store = %v, want dependencies of decorator", node.Deps)
+		}
+	}
+}
+
+func TestHub_DecoratorLifetime(t *testing.T) {
+	type decorated struct{ instance interface{} }
+	tests := []struct {
+		name      string
+		lifetime  Lifetime
+		sameHub   bool // two lookups from hub get the same decorated instance
+		sameScope bool // two lookups from scope get the same decorated instance
+	}{
+		{name: "default", lifetime: LifetimeDefault},
+		{name: "singleton", lifetime: LifetimeSingleton, sameHub: true, sameScope: true},
+		{name: "per-dependent", lifetime: LifetimePerDependent, sameHub: true, sameScope: true},
+		{name: "transient", lifetime: LifetimeTransient},
+		{name: "scoped", lifetime: LifetimeScoped, sameScope: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register("instance-provider", &Spec{
+				Services: []string{"instance"},
+				Lifetime: tt.lifetime,
+				Creator:  func() Provider { return &testLifetimeProvider{} },
+			})
+			r.RegisterDecorator("instance", func(instance interface{}, dc DependencyContext) interface{} {
+				return &decorated{instance}
+			})
+			hub := New(WithRegistry(r))
+			err := hub.Init(map[string]interface{}{"instance-provider": nil}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+			if err != nil {
+				t.Fatalf("Hub.Init() = %v, want nil", err)
+			}
+			if tt.lifetime != LifetimeScoped {
+				if same := hub.Service("instance") == hub.Service("instance"); same != tt.sameHub {
+					t.Errorf("Hub.Service() got same decorated instance = %v, want %v", same, tt.sameHub)
+				}
+			}
+			scope := hub.NewScope(context.Background())
+			defer scope.Close()
+			i1, i2 := scope.Service("instance").(*decorated), scope.Service("instance").(*decorated)
+			if (i1 == i2) != tt.sameScope {
+				t.Errorf("Scope.Service() got same decorated instance = %v, want %v", i1 == i2, tt.sameScope)
+			}
+			if (i1.instance == i2.instance) != tt.sameScope {
+				t.Errorf("Scope.Service() decorated same instance = %v, want %v", i1.instance == i2.instance, tt.sameScope)
+			}
+		})
+	}
+}
+
+func TestHub_DecoratorDependent(t *testing.T) {
+	type consumer struct {
+		Store testStore `autowired:"store"`
+	}
+	r := NewRegistry()
+	RegisterTypedTo[testStore](r, "memory-store", &Spec{
+		Services: []string{"store"},
+		Creator:  func() Provider { return &testStoreImpl{"memory"} },
+	})
+	c1, c2 := &consumer{}, &consumer{}
+	r.Register("consumer-1", &Spec{Creator: func() Provider { return c1 }})
+	r.Register("consumer-2", &Spec{Creator: func() Provider { return c2 }})
+	r.RegisterDecorator("store", func(instance interface{}, dc DependencyContext) interface{} {
+		return &testDecoratedStore{instance.(testStore), dc.Caller() + ":"}
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"memory-store": nil,
+		"consumer-1":   nil,
+		"consumer-2":   nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	if got := c1.Store.Name(); got != "consumer-1:memory" {
+		t.Errorf("consumer-1 Store.Name() = %q, want decorated with its caller", got)
+	}
+	if got := c2.Store.Name(); got != "consumer-2:memory" {
+		t.Errorf("consumer-2 Store.Name() = %q, want decorated with its caller", got)
+	}
+}
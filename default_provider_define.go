@@ -13,7 +13,10 @@ type Spec struct {
 	Description          string                // optional
 	ConfigFunc           func() interface{}    // optional
 	Types                []reflect.Type        // optional
-	Creator              Creator               // required
+	Creator              Creator               // required, unless Constructor is set
+	Constructor          interface{}           // optional, constructor function to create provider, see ProviderConstructor
+	ConstructorArgs      []string              // optional, service names of Constructor parameters
+	Lifetime             Lifetime              // optional, lifetime of instances provided by DependencyProvider
 }
 
 // Register .
@@ -32,6 +35,8 @@ var (
 	_ ServiceDependencies  = (*specDefine)(nil)
 	_ ConfigCreator        = (*specDefine)(nil)
 	_ ConfigCreator        = (*specDefine)(nil)
+	_ ProviderConstructor  = (*specDefine)(nil)
+	_ ProviderLifetime     = (*specDefine)(nil)
 )
 
 type specDefine struct {
@@ -107,6 +112,26 @@ func (d *specDefine) Config() interface{} {
 	return nil
 }
 
+func (d *specDefine) Constructor() (interface{}, []string) {
+	if d.s.Constructor != nil {
+		return d.s.Constructor, d.s.ConstructorArgs
+	}
+	if d, ok := d.s.Define.(ProviderConstructor); ok {
+		return d.Constructor()
+	}
+	return nil, nil
+}
+
+func (d *specDefine) Lifetime() Lifetime {
+	if len(d.s.Lifetime) > 0 {
+		return d.s.Lifetime
+	}
+	if d, ok := d.s.Define.(ProviderLifetime); ok {
+		return d.Lifetime()
+	}
+	return LifetimeDefault
+}
+
 func (d *specDefine) Creator() Creator {
 	if d.s.Creator != nil {
 		return d.s.Creator
@@ -0,0 +1,188 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Cycle is a circular dependency path, Nodes[0] -> Nodes[1] -> ... -> Nodes[0]
+type Cycle struct {
+	// Nodes of the path
+	Nodes []string
+
+	// Edges[i] are the edges from Nodes[i] to the next node, empty if the node has no Edges
+	Edges [][]*Edge
+}
+
+func (c *Cycle) String() string {
+	return strings.Join(append(append([]string{}, c.Nodes...), c.Nodes[0]), " -> ")
+}
+
+// CycleError is returned when the graph can not be resolved because of circular dependencies
+type CycleError struct {
+	// Cycles found in the graph, the shortest cycle for each group of nodes depending on each other
+	Cycles []*Cycle
+
+	// Unresolved nodes, including the nodes depending on cycles
+	Unresolved Graph
+}
+
+func (e *CycleError) Error() string {
+	if len(e.Cycles) <= 0 {
+		var names []string
+		for _, node := range e.Unresolved {
+			names = append(names, node.String())
+		}
+		sort.Strings(names)
+		return fmt.Sprintf("Circular dependency found, unresolved: %s", strings.Join(names, ", "))
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("Circular dependency found:")
+	for _, cycle := range e.Cycles {
+		buf.WriteString("\n\t")
+		buf.WriteString(cycle.String())
+		for i, edges := range cycle.Edges {
+			from, to := cycle.Nodes[i], cycle.Nodes[(i+1)%len(cycle.Nodes)]
+			for _, edge := range edges {
+				if text := edge.text(); len(text) > 0 {
+					fmt.Fprintf(buf, "\n\t\t%s -> %s: %s", from, to, text)
+				}
+			}
+		}
+	}
+	return buf.String()
+}
+
+// findCycles find the shortest cycle of each strongly connected component in graph
+func findCycles(graph Graph) []*Cycle {
+	nodes := make(map[string]*Node)
+	var names []string
+	for _, node := range graph {
+		nodes[node.Name] = node
+		names = append(names, node.Name)
+	}
+	sort.Strings(names)
+	deps := func(name string) []string {
+		var list []string
+		for _, dep := range nodes[name].Deps {
+			if _, ok := nodes[dep]; ok {
+				list = append(list, dep)
+			}
+		}
+		sort.Strings(list)
+		return list
+	}
+
+	// Tarjan's strongly connected components algorithm
+	var (
+		index   int
+		indexes = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		sccs    [][]string
+	)
+	var connect func(name string)
+	connect = func(name string) {
+		indexes[name], lowlink[name] = index, index
+		index++
+		stack = append(stack, name)
+		onStack[name] = true
+		for _, dep := range deps(name) {
+			if _, ok := indexes[dep]; !ok {
+				connect(dep)
+				if lowlink[dep] < lowlink[name] {
+					lowlink[name] = lowlink[dep]
+				}
+			} else if onStack[dep] && indexes[dep] < lowlink[name] {
+				lowlink[name] = indexes[dep]
+			}
+		}
+		if lowlink[name] == indexes[name] {
+			var scc []string
+			for {
+				n := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[n] = false
+				scc = append(scc, n)
+				if n == name {
+					break
+				}
+			}
+			sccs = append(sccs, scc)
+		}
+	}
+	for _, name := range names {
+		if _, ok := indexes[name]; !ok {
+			connect(name)
+		}
+	}
+
+	var cycles []*Cycle
+	for _, scc := range sccs {
+		members := make(map[string]bool)
+		for _, name := range scc {
+			members[name] = true
+		}
+		sort.Strings(scc)
+		var shortest []string
+		for _, start := range scc {
+			path := shortestPath(start, members, deps)
+			if path != nil && (shortest == nil || len(path) < len(shortest)) {
+				shortest = path
+			}
+		}
+		if shortest == nil {
+			continue
+		}
+		cycle := &Cycle{Nodes: shortest}
+		for i, from := range shortest {
+			to := shortest[(i+1)%len(shortest)]
+			var edges []*Edge
+			for _, edge := range nodes[from].Edges {
+				if edge.To == to && !edge.Lazy {
+					edges = append(edges, edge)
+				}
+			}
+			cycle.Edges = append(cycle.Edges, edges)
+		}
+		cycles = append(cycles, cycle)
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].Nodes[0] < cycles[j].Nodes[0] })
+	return cycles
+}
+
+// shortestPath find the shortest path from start back to start through members, by breadth-first search
+func shortestPath(start string, members map[string]bool, deps func(string) []string) []string {
+	prev := make(map[string]string)
+	queue := []string{start}
+	visited := map[string]bool{start: true}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, dep := range deps(name) {
+			if !members[dep] {
+				continue
+			}
+			if dep == start {
+				path := []string{name}
+				for name != start {
+					name = prev[name]
+					path = append(path, name)
+				}
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			if !visited[dep] {
+				visited[dep] = true
+				prev[dep] = name
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return nil
+}
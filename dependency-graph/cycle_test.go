@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+)
+
+func ExampleCycleError() {
+	node1 := NewNode("node1", "node2")
+	node1.Edges = []*Edge{{To: "node2", Service: "db", Source: "service-tag", Field: "DB"}}
+	node2 := NewNode("node2", "node3", "node1")
+	node2.Edges = []*Edge{
+		{To: "node3", Service: "cache", Source: "dependencies"},
+		{To: "node1", Service: "api", Source: "service-tag", Field: "API"},
+	}
+	node3 := NewNode("node3", "node4")
+	node4 := NewNode("node4", "node3")
+	node5 := NewNode("node5", "node1")
+	_, err := Resolve(Graph{node1, node2, node3, node4, node5})
+	var cerr *CycleError
+	if errors.As(err, &cerr) {
+		fmt.Println(len(cerr.Cycles), len(cerr.Unresolved))
+	}
+	fmt.Println(err)
+	// Output:
+	// 2 5
+	// Circular dependency found:
+	// 	node1 -> node2 -> node1
+	// 		node1 -> node2: db (service-tag, DB)
+	// 		node2 -> node1: api (service-tag, API)
+	// 	node3 -> node4 -> node3
+}
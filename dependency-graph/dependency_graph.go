@@ -3,17 +3,42 @@ package graph
 // reference http://dnaeon.github.io/dependency-graph-resolution-algorithm-in-go/
 
 import (
-	"errors"
 	"fmt"
+	"os"
+	"sort"
 )
 
 // Node represents a single node in the graph with it's dependencies
 type Node struct {
 	// Name of the node
-	Name string
+	Name string `json:"name"`
 
 	// Dependencies of the node
-	Deps []string
+	Deps []string `json:"deps"`
+
+	// Labels of the node, optional
+	Labels []string `json:"labels,omitempty"`
+
+	// Edges describe why the node depends on others, optional
+	Edges []*Edge `json:"edges,omitempty"`
+}
+
+// Edge represents a dependency of node with the reason
+type Edge struct {
+	// Name of the node depended on
+	To string `json:"to"`
+
+	// Service which caused the dependency
+	Service string `json:"service,omitempty"`
+
+	// Source of the dependency, such as dependencies, service-tag or autowired-type
+	Source string `json:"source,omitempty"`
+
+	// Field of struct which caused the dependency
+	Field string `json:"field,omitempty"`
+
+	// Lazy dependency is resolved on first use, it is not in Deps of node
+	Lazy bool `json:"lazy,omitempty"`
 }
 
 func (n *Node) String() string {
@@ -37,19 +62,33 @@ type Graph []*Node
 
 // Display the dependency graph
 func (g Graph) Display() {
-	for _, node := range g {
-		if len(node.Deps) <= 0 {
-			fmt.Println(node.Name)
-		} else {
-			for _, dep := range node.Deps {
-				fmt.Printf("%s -> %s\n", node.Name, dep)
-			}
-		}
-	}
+	g.WriteText(os.Stdout)
 }
 
 // Resolve the dependency graph
 func Resolve(graph Graph) (Graph, error) {
+	levels, unresolved := resolve(graph)
+	if len(unresolved) > 0 {
+		return unresolved, &CycleError{Cycles: findCycles(unresolved), Unresolved: unresolved}
+	}
+	var resolved Graph
+	for _, level := range levels {
+		resolved = append(resolved, level...)
+	}
+	return resolved, nil
+}
+
+// ResolveLevels resolve the dependency graph into levels,
+// the nodes of a level only depend on the nodes of previous levels.
+func ResolveLevels(graph Graph) ([]Graph, error) {
+	levels, unresolved := resolve(graph)
+	if len(unresolved) > 0 {
+		return nil, &CycleError{Cycles: findCycles(unresolved), Unresolved: unresolved}
+	}
+	return levels, nil
+}
+
+func resolve(graph Graph) (levels []Graph, unresolved Graph) {
 	// A map containing the node names and the actual node object
 	nodeNames := make(map[string]*Node)
 
@@ -70,7 +109,6 @@ func Resolve(graph Graph) (Graph, error) {
 	// Iteratively find and remove nodes from the graph which have no dependencies.
 	// If at some point there are still nodes in the graph and we cannot find
 	// nodes without dependencies, that means we have a circular dependency
-	var resolved Graph
 	for len(nodeDependencies) != 0 {
 		// Get all nodes from the graph which have no dependencies
 		readySet := make(map[string]struct{})
@@ -82,18 +120,20 @@ func Resolve(graph Graph) (Graph, error) {
 
 		// If there aren't any ready nodes, then we have a cicular dependency
 		if len(readySet) == 0 {
-			var g Graph
 			for name := range nodeDependencies {
-				g = append(g, nodeNames[name])
+				unresolved = append(unresolved, nodeNames[name])
 			}
-			return g, errors.New("Circular dependency found")
+			return levels, unresolved
 		}
 
-		// Remove the ready nodes and add them to the resolved graph
+		// Remove the ready nodes and add them to the resolved levels
+		var level Graph
 		for name := range readySet {
 			delete(nodeDependencies, name)
-			resolved = append(resolved, nodeNames[name])
+			level = append(level, nodeNames[name])
 		}
+		sort.Slice(level, func(i, j int) bool { return level[i].Name < level[j].Name })
+		levels = append(levels, level)
 
 		// Also make sure to remove the ready nodes from the
 		// remaining node dependencies as well
@@ -108,5 +148,5 @@ func Resolve(graph Graph) (Graph, error) {
 		}
 	}
 
-	return resolved, nil
+	return levels, nil
 }
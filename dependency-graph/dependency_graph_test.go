@@ -16,7 +16,8 @@ func Example_circular() {
 	}
 	fmt.Println("OK")
 	// Output:
-	// Circular dependency found
+	// Circular dependency found:
+	// 	node1 -> node2 -> node3 -> node1
 }
 
 func Example_ok() {
@@ -39,3 +40,32 @@ func Example_ok() {
 	// node2 -> node3
 	// node1 -> node2
 }
+
+func Example_levels() {
+	node1 := NewNode("node1", "node2", "node3")
+	node2 := NewNode("node2", "node4")
+	node3 := NewNode("node3", "node4")
+	node4 := NewNode("node4")
+	node5 := NewNode("node5")
+	var g Graph
+	g = append(g, node1, node2, node3, node4, node5)
+	levels, err := ResolveLevels(g)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for i, level := range levels {
+		fmt.Println("level", i)
+		level.Display()
+	}
+	// Output:
+	// level 0
+	// node4
+	// node5
+	// level 1
+	// node2 -> node4
+	// node3 -> node4
+	// level 2
+	// node1 -> node2
+	// node1 -> node3
+}
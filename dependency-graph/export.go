@@ -0,0 +1,187 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// formats to export the dependency graph
+const (
+	FormatText    = "text"
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+	FormatJSON    = "json"
+)
+
+// Export write the dependency graph to w in the format, one of text, dot, mermaid and json
+func (g Graph) Export(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		return g.WriteText(w)
+	case FormatDOT:
+		return g.WriteDOT(w)
+	case FormatMermaid:
+		return g.WriteMermaid(w)
+	case FormatJSON:
+		return g.WriteJSON(w)
+	}
+	return fmt.Errorf("unknown graph format %q", format)
+}
+
+// WriteText write the dependency graph as "a -> b" lines
+func (g Graph) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, node := range g {
+		lazy := node.lazyDeps()
+		if len(node.Deps) <= 0 && len(lazy) <= 0 {
+			fmt.Fprintln(bw, node.Name)
+		} else {
+			for _, dep := range node.Deps {
+				fmt.Fprintf(bw, "%s -> %s\n", node.Name, dep)
+			}
+			for _, dep := range lazy {
+				fmt.Fprintf(bw, "%s -> %s (lazy)\n", node.Name, dep)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteDOT write the dependency graph in Graphviz DOT language
+func (g Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph G {")
+	for _, node := range g {
+		if len(node.Labels) > 0 {
+			fmt.Fprintf(bw, "\t%s [label=%s];\n", dotQuote(node.Name), dotQuote(node.Name+"\n"+labelsText(node.Labels)))
+		} else {
+			fmt.Fprintf(bw, "\t%s;\n", dotQuote(node.Name))
+		}
+	}
+	for _, node := range g {
+		for _, edge := range node.edges() {
+			var attrs []string
+			if text := edge.text(); len(text) > 0 {
+				attrs = append(attrs, "label="+dotQuote(text))
+			}
+			if edge.Lazy {
+				attrs = append(attrs, "style=dashed")
+			}
+			if len(attrs) > 0 {
+				fmt.Fprintf(bw, "\t%s -> %s [%s];\n", dotQuote(node.Name), dotQuote(edge.To), strings.Join(attrs, ", "))
+			} else {
+				fmt.Fprintf(bw, "\t%s -> %s;\n", dotQuote(node.Name), dotQuote(edge.To))
+			}
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteMermaid write the dependency graph as Mermaid flowchart
+func (g Graph) WriteMermaid(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "graph TD")
+	ids := make(map[string]string)
+	id := func(name string) string {
+		if id, ok := ids[name]; ok {
+			return id
+		}
+		ids[name] = fmt.Sprintf("n%d", len(ids))
+		return ids[name]
+	}
+	for _, node := range g {
+		text := node.Name
+		if len(node.Labels) > 0 {
+			text += "<br/>" + labelsText(node.Labels)
+		}
+		fmt.Fprintf(bw, "\t%s[%s]\n", id(node.Name), mermaidQuote(text))
+	}
+	for _, node := range g {
+		for _, edge := range node.edges() {
+			arrow := "-->"
+			if edge.Lazy {
+				arrow = "-.->"
+			}
+			if text := edge.text(); len(text) > 0 {
+				fmt.Fprintf(bw, "\t%s %s|%s| %s\n", id(node.Name), arrow, mermaidQuote(text), id(edge.To))
+			} else {
+				fmt.Fprintf(bw, "\t%s %s %s\n", id(node.Name), arrow, id(edge.To))
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteJSON write the dependency graph as JSON array of nodes
+func (g Graph) WriteJSON(w io.Writer) error {
+	nodes := g
+	if nodes == nil {
+		nodes = Graph{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(nodes)
+}
+
+// edges return Edges of node, or edges without reason built from Deps
+func (n *Node) edges() []*Edge {
+	if len(n.Edges) > 0 {
+		return n.Edges
+	}
+	edges := make([]*Edge, 0, len(n.Deps))
+	for _, dep := range n.Deps {
+		edges = append(edges, &Edge{To: dep})
+	}
+	return edges
+}
+
+// lazyDeps return targets of lazy edges which are not in Deps
+func (n *Node) lazyDeps() (list []string) {
+	seen := make(map[string]bool)
+	for _, dep := range n.Deps {
+		seen[dep] = true
+	}
+	for _, edge := range n.Edges {
+		if edge.Lazy && !seen[edge.To] {
+			seen[edge.To] = true
+			list = append(list, edge.To)
+		}
+	}
+	return list
+}
+
+func (e *Edge) text() string {
+	var reason []string
+	if len(e.Source) > 0 {
+		reason = append(reason, e.Source)
+	}
+	if len(e.Field) > 0 {
+		reason = append(reason, e.Field)
+	}
+	text := e.Service
+	if len(reason) > 0 {
+		if len(text) > 0 {
+			text += " "
+		}
+		text += "(" + strings.Join(reason, ", ") + ")"
+	}
+	return text
+}
+
+func labelsText(labels []string) string {
+	return "@" + strings.Join(labels, ", @")
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"fmt"
+	"os"
+)
+
+func exampleGraph() Graph {
+	node1 := NewNode("node1", "node2", "node3")
+	node1.Edges = []*Edge{
+		{To: "node2", Service: "db", Source: "dependencies"},
+		{To: "node3", Service: "cache@redis", Source: "service-tag", Field: "Cache"},
+	}
+	node2 := NewNode("node2")
+	node3 := NewNode("node3")
+	node3.Labels = []string{"redis", "memory"}
+	return Graph{node1, node2, node3}
+}
+
+func ExampleGraph_WriteDOT() {
+	exampleGraph().WriteDOT(os.Stdout)
+	// Output:
+	// digraph G {
+	// 	"node1";
+	// 	"node2";
+	// 	"node3" [label="node3\n@redis, @memory"];
+	// 	"node1" -> "node2" [label="db (dependencies)"];
+	// 	"node1" -> "node3" [label="cache@redis (service-tag, Cache)"];
+	// }
+}
+
+func ExampleGraph_WriteMermaid() {
+	exampleGraph().WriteMermaid(os.Stdout)
+	// Output:
+	// graph TD
+	// 	n0["node1"]
+	// 	n1["node2"]
+	// 	n2["node3<br/>@redis, @memory"]
+	// 	n0 -->|"db (dependencies)"| n1
+	// 	n0 -->|"cache@redis (service-tag, Cache)"| n2
+}
+
+func ExampleGraph_WriteJSON() {
+	g := Graph{NewNode("node1", "node2"), NewNode("node2")}
+	g.WriteJSON(os.Stdout)
+	// Output:
+	// [
+	//   {
+	//     "name": "node1",
+	//     "deps": [
+	//       "node2"
+	//     ]
+	//   },
+	//   {
+	//     "name": "node2",
+	//     "deps": null
+	//   }
+	// ]
+}
+
+func ExampleGraph_Export() {
+	err := exampleGraph().Export(os.Stdout, "svg")
+	fmt.Println(err)
+	// Output:
+	// unknown graph format "svg"
+}
+
+func ExampleGraph_WriteText_lazy() {
+	node1 := NewNode("node1", "node2")
+	node2 := NewNode("node2")
+	node2.Edges = []*Edge{
+		{To: "node1", Service: "node1", Source: "service-tag", Field: "Node1", Lazy: true},
+	}
+	g := Graph{node1, node2}
+	g.WriteText(os.Stdout)
+	g.WriteMermaid(os.Stdout)
+	// Output:
+	// node1 -> node2
+	// node2 -> node1 (lazy)
+	// graph TD
+	// 	n0["node1"]
+	// 	n1["node2"]
+	// 	n0 --> n1
+	// 	n1 -.->|"node1 (service-tag, Node1)"| n0
+}
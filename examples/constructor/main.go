@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/recallsong/servicehub"
+	"github.com/recallsong/servicehub/logs"
+)
+
+type config struct {
+	Name string `file:"name" default:"recallsong"`
+}
+
+// Greeter .
+type Greeter interface {
+	Greet(name string) string
+}
+
+type greeter struct{}
+
+func (g *greeter) Greet(name string) string { return "hello " + name }
+
+type provider struct {
+	cfg     *config
+	log     logs.Logger
+	greeter Greeter
+}
+
+// newProvider all parameters are injected, the provider can have unexported fields
+func newProvider(cfg *config, log logs.Logger, greeter Greeter) (*provider, error) {
+	if len(cfg.Name) <= 0 {
+		return nil, fmt.Errorf("name is empty")
+	}
+	p := &provider{cfg: cfg, log: log, greeter: greeter}
+	p.log.Info(p.greeter.Greet(p.cfg.Name))
+	return p, nil
+}
+
+func init() {
+	servicehub.Register("greeter-provider", &servicehub.Spec{
+		Types: []reflect.Type{reflect.TypeOf((*Greeter)(nil)).Elem()},
+		Creator: func() servicehub.Provider {
+			return &greeter{}
+		},
+	})
+	servicehub.Register("hello-provider", &servicehub.Spec{
+		Services:    []string{"hello"},
+		Description: "hello for example",
+		ConfigFunc:  func() interface{} { return &config{} },
+		Constructor: newProvider,
+	})
+}
+
+func main() {
+	hub := servicehub.New()
+	hub.Run("examples", "", os.Args...)
+}
@@ -11,9 +11,9 @@ func getService(t *testing.T) Interface {
 		Content: `
 example-provider:
 `})
-	example, ok := hub.Service("example").(Interface)
-	if !ok {
-		t.Fatalf("example is not Interface")
+	example, err := servicehub.Get[Interface](hub, "example")
+	if err != nil {
+		t.Fatal(err)
 	}
 	return example
 }
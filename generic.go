@@ -0,0 +1,120 @@
+package servicehub
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// serviceGetter is implemented by Hub and Context
+type serviceGetter interface {
+	Service(name string, options ...interface{}) interface{}
+}
+
+// ErrServiceNotFound is returned by Get and GetByType if no provider provides the service.
+var ErrServiceNotFound = errors.New("service not found")
+
+// TypeOf return the reflect.Type of T, it is useful to declare Spec.Types of interface.
+func TypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Get return the service of name as T, an error wrapping ErrServiceNotFound is returned if the service is absent,
+// and other errors if the service can not be resolved or it is not a T.
+func Get[T any](s serviceGetter, name string, options ...interface{}) (T, error) {
+	var zero T
+	instance, err := lookupService(s, name, options...)
+	if err != nil {
+		return zero, fmt.Errorf("failed to get service %q: %w", name, err)
+	}
+	if instance == nil {
+		return zero, fmt.Errorf("service %q: %w", name, ErrServiceNotFound)
+	}
+	service, ok := instance.(T)
+	if !ok {
+		return zero, fmt.Errorf("service %q is %T, not %s", name, instance, TypeOf[T]())
+	}
+	return service, nil
+}
+
+// lookupService return the service of name and the error of resolving it, which Service only logs.
+func lookupService(s serviceGetter, name string, options ...interface{}) (interface{}, error) {
+	var (
+		hub                 *Hub
+		caller, callerLabel string
+	)
+	switch s := s.(type) {
+	case *Hub:
+		hub = s
+	case *providerContext:
+		hub, caller, callerLabel = s.hub, s.name, s.label
+	default:
+		return s.Service(name, options...), nil
+	}
+	return hub.getService(newDependencyContext(
+		name,
+		caller,
+		callerLabel,
+		nil,
+		reflect.StructTag(""),
+	), options...)
+}
+
+// MustGet is like Get but panics if the service is absent or not a T.
+func MustGet[T any](s serviceGetter, name string, options ...interface{}) T {
+	service, err := Get[T](s, name, options...)
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+// GetByType return the service of the provider which declared type T in Types.
+func GetByType[T any](h *Hub, options ...interface{}) (T, error) {
+	var zero T
+	typ := TypeOf[T]()
+	instance, err := h.getService(newDependencyContext(
+		"",
+		"",
+		"",
+		typ,
+		autowiredTag,
+	), options...)
+	if err != nil {
+		return zero, err
+	}
+	if instance == nil {
+		return zero, fmt.Errorf("service of type %s: %w", typ, ErrServiceNotFound)
+	}
+	service, ok := instance.(T)
+	if !ok {
+		return zero, fmt.Errorf("service of type %s is %T", typ, instance)
+	}
+	return service, nil
+}
+
+// RegisterTyped is like Register, but add T to Spec.Types if it is absent.
+func RegisterTyped[T any](name string, spec *Spec) {
+	Register(name, withType[T](spec))
+}
+
+// RegisterTypedTo is like Registry.Register, but add T to Spec.Types if it is absent.
+func RegisterTypedTo[T any](r *Registry, name string, spec *Spec) error {
+	return r.Register(name, withType[T](spec))
+}
+
+// withType return a copy of spec with T in Types, spec of caller is not changed.
+func withType[T any](spec *Spec) *Spec {
+	if spec == nil {
+		return nil
+	}
+	typ := TypeOf[T]()
+	for _, t := range spec.Types {
+		if t == typ {
+			return spec
+		}
+	}
+	copied := *spec
+	copied.Types = append(append([]reflect.Type(nil), spec.Types...), typ)
+	return &copied
+}
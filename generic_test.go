@@ -0,0 +1,89 @@
+package servicehub
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestGet(t *testing.T) {
+	r := NewRegistry()
+	RegisterTypedTo[testStore](r, "memory-store", &Spec{
+		Services: []string{"store"},
+		Creator:  func() Provider { return &testStoreImpl{"memory"} },
+	})
+	r.Register("string-provider", &Spec{
+		Services: []string{"string"},
+		Creator:  func() Provider { return "text" },
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"memory-store":    nil,
+		"string-provider": nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+
+	if s, err := Get[testStore](hub, "store"); err != nil || s.Name() != "memory" {
+		t.Errorf("Get[testStore]() = %v, %v, want memory store", s, err)
+	}
+	if _, err := Get[testStore](hub, "not-exist"); !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("Get[testStore]() of absent service = %v, want ErrServiceNotFound", err)
+	}
+	if _, err := Get[testStore](hub, "string"); err == nil || errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("Get[testStore]() of wrong type = %v, want type error", err)
+	}
+	if s, err := GetByType[testStore](hub); err != nil || s.Name() != "memory" {
+		t.Errorf("GetByType[testStore]() = %v, %v, want memory store", s, err)
+	}
+	if _, err := GetByType[*testCtorDBImpl](hub); !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("GetByType[*testCtorDBImpl]() of absent type = %v, want ErrServiceNotFound", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("MustGet[testStore]() of wrong type, want panic")
+			}
+		}()
+		MustGet[testStore](hub, "string")
+	}()
+}
+
+func TestGetResolveError(t *testing.T) {
+	r := NewRegistry()
+	r.Register("store-a", &Spec{
+		Services: []string{"store"},
+		Creator:  func() Provider { return &testStoreImpl{"a"} },
+	})
+	r.Register("store-b", &Spec{
+		Services: []string{"store"},
+		Creator:  func() Provider { return &testStoreImpl{"b"} },
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"store-a": nil,
+		"store-b": nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	if _, err := Get[testStore](hub, "store"); err == nil || errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("Get[testStore]() of ambiguous service = %v, want resolve error", err)
+	}
+}
+
+func TestRegisterTypedTo(t *testing.T) {
+	spec := &Spec{
+		Services: []string{"store"},
+		Creator:  func() Provider { return &testStoreImpl{"memory"} },
+	}
+	r := NewRegistry()
+	if err := RegisterTypedTo[testStore](r, "memory-store", spec); err != nil {
+		t.Fatalf("RegisterTypedTo() = %v, want nil", err)
+	}
+	if len(spec.Types) != 0 {
+		t.Errorf("RegisterTypedTo() changed Types of spec to %v", spec.Types)
+	}
+}
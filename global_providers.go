@@ -5,8 +5,6 @@ import (
 	"os"
 )
 
-var globalProviders = make(map[string]ProviderDefine)
-
 // RegisterGlobalSpec .
 func RegisterGlobalSpec(name string, spec *Spec) {
 	RegisterGlobalProvider(name, &specDefine{spec})
@@ -14,9 +12,9 @@ func RegisterGlobalSpec(name string, spec *Spec) {
 
 // RegisterGlobalProvider .
 func RegisterGlobalProvider(name string, define ProviderDefine) {
-	if _, ok := globalProviders[name]; ok {
-		fmt.Printf("global provider %s already exist\n", name)
+	err := defaultRegistry.RegisterGlobalProvider(name, define)
+	if err != nil {
+		fmt.Println(err)
 		os.Exit(-1)
 	}
-	globalProviders[name] = define
 }
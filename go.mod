@@ -1,16 +1,21 @@
 module github.com/recallsong/servicehub
 
-go 1.14
+go 1.18
+
+require (
+	github.com/recallsong/go-utils v1.1.0
+	github.com/recallsong/unmarshal v0.0.0-20200326184919-e975eee0738b
+	github.com/sirupsen/logrus v1.8.1
+	github.com/spf13/pflag v1.0.5
+)
 
 require (
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/magiconair/properties v1.8.1 // indirect
 	github.com/mitchellh/mapstructure v1.1.2 // indirect
 	github.com/pelletier/go-toml v1.6.0 // indirect
-	github.com/recallsong/go-utils v1.1.0
-	github.com/recallsong/unmarshal v0.0.0-20200326184919-e975eee0738b
-	github.com/sirupsen/logrus v1.8.1
 	github.com/smartystreets/goconvey v1.6.4 // indirect
-	github.com/spf13/pflag v1.0.5
+	golang.org/x/sys v0.0.0-20191026070338-33540a1f6037 // indirect
 	gopkg.in/ini.v1 v1.55.0 // indirect
+	gopkg.in/yaml.v2 v2.2.4 // indirect
 )
@@ -0,0 +1,131 @@
+package servicehub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HealthCheckKind is the kind of health check, liveness or readiness.
+type HealthCheckKind string
+
+// health check kinds
+const (
+	Liveness  HealthCheckKind = "liveness"  // whether the provider is working, or it should be restarted
+	Readiness HealthCheckKind = "readiness" // whether the provider is able to serve requests
+)
+
+// HealthStatus .
+type HealthStatus string
+
+// health status
+const (
+	HealthUp   HealthStatus = "up"
+	HealthDown HealthStatus = "down"
+)
+
+// HealthChecker is implemented by providers that can report their health.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context, kind HealthCheckKind) error
+}
+
+// ProviderHealth is the health check result of a provider.
+type ProviderHealth struct {
+	Key     string        `json:"key"`
+	Name    string        `json:"name"`
+	Label   string        `json:"label,omitempty"`
+	Status  HealthStatus  `json:"status"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// HealthReport is the aggregated health of all providers implemented HealthChecker.
+type HealthReport struct {
+	Kind      HealthCheckKind   `json:"kind"`
+	Status    HealthStatus      `json:"status"`
+	Error     string            `json:"error,omitempty"`
+	Providers []*ProviderHealth `json:"providers"`
+}
+
+// Health run the health checks of all providers concurrently, each check is limited by the health check timeout.
+// The readiness is down before the hub is started.
+func (h *Hub) Health(ctx context.Context, kind HealthCheckKind) *HealthReport {
+	h.lock.RLock()
+	started := h.started
+	providers := h.providers
+	h.lock.RUnlock()
+
+	report := &HealthReport{Kind: kind, Status: HealthUp}
+	var wg sync.WaitGroup
+	for _, pc := range providers {
+		checker, ok := pc.provider.(HealthChecker)
+		if !ok {
+			continue
+		}
+		ph := &ProviderHealth{
+			Key:   pc.key,
+			Name:  pc.name,
+			Label: pc.label,
+		}
+		report.Providers = append(report.Providers, ph)
+		wg.Add(1)
+		go func(pc *providerContext, checker HealthChecker, ph *ProviderHealth) {
+			defer wg.Done()
+			now := time.Now()
+			err := h.checkHealth(ctx, pc, checker, kind)
+			ph.Latency = time.Since(now)
+			if err != nil {
+				ph.Status = HealthDown
+				ph.Error = err.Error()
+			} else {
+				ph.Status = HealthUp
+			}
+		}(pc, checker, ph)
+	}
+	wg.Wait()
+
+	if kind == Readiness && !started {
+		report.Status = HealthDown
+		report.Error = "service hub is not started"
+	}
+	for _, ph := range report.Providers {
+		if ph.Status != HealthUp {
+			report.Status = HealthDown
+		}
+	}
+	return report
+}
+
+func (h *Hub) checkHealth(ctx context.Context, pc *providerContext, checker HealthChecker, kind HealthCheckKind) error {
+	h.lock.RLock()
+	ready := pc.ready // set by Start under the lock, readyErr is set before ready is closed
+	h.lock.RUnlock()
+	if kind == Readiness && ready != nil {
+		select {
+		case <-ready:
+			if pc.readyErr != nil {
+				return pc.readyErr
+			}
+		default:
+			return fmt.Errorf("provider is starting")
+		}
+	}
+	if h.healthTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.healthTimeout)
+		defer cancel()
+	}
+	ch := make(chan error, 1)
+	go func() {
+		ch <- h.recoverFunc(pc.key, "CheckHealth", func(ctx context.Context) error {
+			return checker.CheckHealth(ctx, kind)
+		})(ctx)
+	}()
+	select {
+	case err := <-ch:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("health check: %s", ctx.Err())
+	}
+}
@@ -0,0 +1,113 @@
+package servicehub
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type testHealthProvider struct {
+	check func(ctx context.Context) error
+}
+
+func (p *testHealthProvider) CheckHealth(ctx context.Context, kind HealthCheckKind) error {
+	return p.check(ctx)
+}
+
+func TestHub_Health(t *testing.T) {
+	providers := []testDefine{
+		testRegister("test1", nil, nil, func() Provider {
+			return &testHealthProvider{func(ctx context.Context) error { return nil }}
+		}),
+		testRegister("test2", nil, nil, func() Provider {
+			return &testHealthProvider{func(ctx context.Context) error { return fmt.Errorf("connection refused") }}
+		}),
+		testRegister("test3", nil, nil, func() Provider {
+			return &testHealthProvider{func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}}
+		}),
+		testRegister("test4", nil, nil, nil),
+	}
+	cfg := map[string]interface{}{}
+	for _, p := range providers {
+		Register(p.name, p.spec)
+		cfg[p.name] = nil
+	}
+	defer func() {
+		for _, p := range providers {
+			defaultRegistry.Unregister(p.name)
+		}
+	}()
+
+	hub := New(WithHealthCheckTimeout(50 * time.Millisecond))
+	err := hub.Init(cfg, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	report := hub.Health(context.Background(), Liveness)
+	if report.Status != HealthDown {
+		t.Errorf("HealthReport.Status = %s, want %s", report.Status, HealthDown)
+	}
+	want := map[string]HealthStatus{
+		testProviderName("test1"): HealthUp,
+		testProviderName("test2"): HealthDown,
+		testProviderName("test3"): HealthDown,
+	}
+	if len(report.Providers) != len(want) {
+		t.Fatalf("got %d providers in report, want %d", len(report.Providers), len(want))
+	}
+	for _, ph := range report.Providers {
+		if ph.Status != want[ph.Key] {
+			t.Errorf("provider %s health status = %s, want %s", ph.Key, ph.Status, want[ph.Key])
+		}
+		if ph.Status == HealthDown && len(ph.Error) <= 0 {
+			t.Errorf("provider %s health error is empty", ph.Key)
+		}
+	}
+
+	report = hub.Health(context.Background(), Readiness)
+	if report.Status != HealthDown || len(report.Error) <= 0 {
+		t.Errorf("readiness before start = %s, want %s", report.Status, HealthDown)
+	}
+}
+
+func TestHub_HealthWhileStarting(t *testing.T) {
+	r := NewRegistry()
+	r.Register("health-provider", &Spec{
+		Creator: func() Provider {
+			return &testHealthProvider{func(ctx context.Context) error { return nil }}
+		},
+	})
+	hub := New(WithRegistry(r), WithOrderedStart(0))
+	err := hub.Init(map[string]interface{}{"health-provider": nil}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				hub.Health(context.Background(), Readiness)
+			}
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := hub.Start(); err != nil {
+		t.Errorf("Hub.Start() = %v, want nil", err)
+	}
+	close(stop)
+	<-done
+	if report := hub.Health(context.Background(), Readiness); report.Status != HealthUp {
+		t.Errorf("readiness after start = %s, want %s", report.Status, HealthUp)
+	}
+	hub.Close()
+}
@@ -1,14 +1,13 @@
 package servicehub
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -27,8 +26,22 @@ import (
 // Hub .
 type Hub struct {
 	logger        logs.Logger
+	registry      *Registry
+	decorators    []*decorator
+	decorated     sync.Map // decorated instances shared by lookups, see decorate
+	bindings      map[string]string
+	config        map[string]interface{}
+	configSources map[string]string
+	configLoader  *configLoader
+	reloadConfig  bool
+	strictConfig  bool
+	watchInterval time.Duration
+	reloadLock    sync.Mutex
+	args          []string
 	providersMap  map[string][]*providerContext
 	providers     []*providerContext
+	levels        [][]*providerContext
+	graph         graph.Graph
 	servicesMap   map[string][]*providerContext
 	servicesTypes map[reflect.Type][]*providerContext
 	lock          sync.RWMutex
@@ -36,15 +49,24 @@ type Hub struct {
 	started     bool
 	ctx         context.Context
 	cancel      func()
-	wg          sync.WaitGroup
+	tasks       sync.Map
 	exitTimeout time.Duration
 
+	orderedStart bool
+	readyTimeout time.Duration
+	parallelInit bool
+	panicMode    PanicMode
+
+	healthTimeout time.Duration
+
 	listeners []Listener
 }
 
 // New .
 func New(options ...interface{}) *Hub {
-	hub := &Hub{}
+	hub := &Hub{
+		healthTimeout: 5 * time.Second,
+	}
 	hub.ctx, hub.cancel = context.WithCancel(context.Background())
 	for _, opt := range options {
 		processOptions(hub, opt)
@@ -52,6 +74,9 @@ func New(options ...interface{}) *Hub {
 	if hub.logger == nil {
 		hub.logger = logrusx.New()
 	}
+	if hub.registry == nil {
+		hub.registry = defaultRegistry
+	}
 	return hub
 }
 
@@ -68,6 +93,12 @@ func (h *Hub) Init(config map[string]interface{}, flags *pflag.FlagSet, args []s
 			return err
 		}
 	}
+	config, err = interpolateConfig(config)
+	if err != nil {
+		return err
+	}
+	h.args = args
+	h.config = config
 	err = h.loadProviders(config)
 	if err != nil {
 		return err
@@ -75,11 +106,13 @@ func (h *Hub) Init(config map[string]interface{}, flags *pflag.FlagSet, args []s
 
 	depGraph, err := h.resolveDependency(h.providersMap)
 	if err != nil {
-		return fmt.Errorf("failed to resolve dependency: %s", err)
+		return fmt.Errorf("failed to resolve dependency: %w", err)
 	}
 
 	flags.BoolP("providers", "p", false, "print all providers supported")
-	flags.BoolP("graph", "g", false, "print providers dependency graph")
+	flags.StringP("graph", "g", "", "print providers dependency graph, format: text, dot, mermaid or json")
+	flags.Lookup("graph").NoOptDefVal = graph.FormatText
+	flags.BoolVar(&h.strictConfig, "config.strict", h.strictConfig, "reject config keys not mapped to any field of provider config")
 	for _, ctx := range h.providers {
 		err = ctx.BindConfig(flags)
 		if err != nil {
@@ -93,26 +126,40 @@ func (h *Hub) Init(config map[string]interface{}, flags *pflag.FlagSet, args []s
 		return err
 	}
 	if ok, err := flags.GetBool("providers"); err == nil && ok {
-		usage := Usage()
+		usage := h.registry.Usage()
 		fmt.Println(usage)
 		os.Exit(0)
 	}
-	if ok, err := flags.GetBool("graph"); err == nil && ok {
-		depGraph.Display()
+	if format := graphFormat(flags); len(format) > 0 {
+		err = depGraph.Export(os.Stdout, format)
+		if err != nil {
+			return err
+		}
 		os.Exit(0)
 	}
-	for _, ctx := range h.providers {
-		h.logger.Infof("provider %s is initializing", ctx.key)
-		now := time.Now()
-		err = ctx.Init()
+	if h.strictConfig {
+		err = h.checkUnknownConfig()
 		if err != nil {
 			return err
 		}
-		dependencies := ctx.dependencies()
-		if len(dependencies) > 0 {
-			h.logger.Infof("provider %s (depends %s) initialized, took %s", ctx.key, dependencies, time.Since(now))
-		} else {
-			h.logger.Infof("provider %s initialized, took %s", ctx.key, time.Since(now))
+	}
+	err = h.validateConfigs()
+	if err != nil {
+		return err
+	}
+	if h.parallelInit {
+		for _, level := range h.levels {
+			err = h.initProviders(level)
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		for _, ctx := range h.providers {
+			err = h.initProvider(ctx)
+			if err != nil {
+				return err
+			}
 		}
 	}
 	for i := len(h.listeners) - 1; i >= 0; i-- {
@@ -124,6 +171,56 @@ func (h *Hub) Init(config map[string]interface{}, flags *pflag.FlagSet, args []s
 	return nil
 }
 
+func (h *Hub) initProvider(ctx *providerContext) error {
+	h.logger.Infof("provider %s is initializing", ctx.key)
+	now := time.Now()
+	err := ctx.Init()
+	if err != nil {
+		return err
+	}
+	dependencies := ctx.dependencies()
+	if len(dependencies) > 0 {
+		h.logger.Infof("provider %s (depends %s) initialized, took %s", ctx.key, dependencies, time.Since(now))
+	} else {
+		h.logger.Infof("provider %s initialized, took %s", ctx.key, time.Since(now))
+	}
+	return nil
+}
+
+// initProviders init providers concurrently, they must not depend on each other.
+func (h *Hub) initProviders(providers []*providerContext) error {
+	if len(providers) == 1 {
+		return h.initProvider(providers[0])
+	}
+	errs := make([]error, len(providers))
+	var wg sync.WaitGroup
+	for i, ctx := range providers {
+		wg.Add(1)
+		go func(i int, ctx *providerContext) {
+			defer wg.Done()
+			errs[i] = h.initProvider(ctx)
+		}(i, ctx)
+	}
+	wg.Wait()
+	return errorx.NewMultiError(errs...).MaybeUnwrap()
+}
+
+// graphFormat return the format of --graph flag to print the dependency graph, empty if it is not printed.
+// Boolean values are accepted as before formats were supported, such as --graph=false.
+func graphFormat(flags *pflag.FlagSet) string {
+	format, err := flags.GetString("graph")
+	if err != nil {
+		return ""
+	}
+	if ok, err := strconv.ParseBool(format); err == nil {
+		if ok {
+			return graph.FormatText
+		}
+		return ""
+	}
+	return format
+}
+
 func (h *Hub) resolveDependency(providersMap map[string][]*providerContext) (graph.Graph, error) {
 	services := map[string][]*providerContext{}
 	types := map[reflect.Type][]*providerContext{}
@@ -134,10 +231,7 @@ func (h *Hub) resolveDependency(providersMap map[string][]*providerContext) (gra
 			list = ps.Services()
 		}
 		for _, s := range list {
-			if exist, ok := services[s]; ok {
-				return nil, fmt.Errorf("service %s conflict between %s and %s", s, exist[0].name, p[0].name)
-			}
-			services[s] = p
+			services[s] = append(services[s], p...)
 		}
 		if ts, ok := d.(ServiceTypes); ok {
 			for _, t := range ts.Types() {
@@ -148,62 +242,149 @@ func (h *Hub) resolveDependency(providersMap map[string][]*providerContext) (gra
 			}
 		}
 	}
+	// a service can be provided by many providers, keep them in stable order
+	for _, list := range services {
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].name != list[j].name {
+				return list[i].name < list[j].name
+			}
+			return list[i].label < list[j].label
+		})
+	}
 	h.servicesMap = services
+	h.decorators = h.registry.decoratorList()
 	h.servicesTypes = types
+	if err := h.checkBindings(services); err != nil {
+		return nil, err
+	}
 	var depGraph graph.Graph
 	for name, p := range providersMap {
-		providers := map[string]*providerContext{}
-		dependsServices, dependsProviders := p[0].Dependencies()
-	loop:
-		for _, service := range dependsServices {
-			name := service
-			var label string
-			idx := strings.Index(service, "@")
-			if idx > 0 {
-				name, label = service[0:idx], service[idx+1:]
-			}
-			if deps, ok := services[name]; ok {
-				if len(label) > 0 {
-					for _, dep := range deps {
-						if dep.label == label {
-							providers[dep.name] = dep
-							continue loop
+		node := graph.NewNode(name)
+		deps := make(map[string]bool)
+		list, err := p[0].dependencyList()
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", p[0].name, err)
+		}
+		for _, dep := range list {
+			providers := []string{dep.provider}
+			service := dep.service
+			if len(dep.provider) <= 0 {
+				providers = nil
+				for _, pc := range p {
+					service = h.binding(pc.name, pc.label, dep.service)
+					found, err := h.dependencyProviders(pc, dep, service)
+					if err != nil {
+						return nil, fmt.Errorf("provider %s depends on %s: %w", pc.key, service, err)
+					}
+					if len(found) <= 0 {
+						return nil, fmt.Errorf("provider %s depends on service %s, but it not found", p[0].name, service)
+					}
+					for _, name := range found {
+						if !containsString(providers, name) {
+							providers = append(providers, name)
 						}
 					}
-				} else if len(deps) > 0 {
-					providers[deps[0].name] = deps[0]
-					continue loop
 				}
 			}
-			return nil, fmt.Errorf("provider %s depends on service %s, but it not found", p[0].name, service)
-		}
-		node := graph.NewNode(name)
-		for dep := range providers {
-			node.Deps = append(node.Deps, dep)
+			for _, provider := range providers {
+				if dep.source != dependencySourceDefine && dep.source != dependencySourceDecorator &&
+					providersMap[provider][0].lifetime == LifetimeScoped {
+					return nil, fmt.Errorf("provider %s can not inject scoped provider %s, lookup it through Scope.Service", p[0].name, provider)
+				}
+				if !dep.lazy && !deps[provider] {
+					node.Deps = append(node.Deps, provider)
+					deps[provider] = true
+				}
+				node.Edges = append(node.Edges, &graph.Edge{
+					To:      provider,
+					Service: service,
+					Source:  dep.source,
+					Field:   dep.field,
+					Lazy:    dep.lazy,
+				})
+			}
 		}
-		for _, dep := range dependsProviders {
-			if _, ok := providers[dep]; !ok {
-				node.Deps = append(node.Deps, dep)
+		for _, pc := range p {
+			if len(pc.label) > 0 {
+				node.Labels = append(node.Labels, pc.label)
 			}
+			pc.depends = node.Deps
 		}
 		depGraph = append(depGraph, node)
 	}
-	resolved, err := graph.Resolve(depGraph)
+	levels, err := graph.ResolveLevels(depGraph)
 	if err != nil {
-		depGraph.Display()
 		return depGraph, err
 	}
+	var resolved graph.Graph
 	var providers []*providerContext
-	for _, node := range resolved {
-		providers = append(providers, providersMap[node.Name]...)
+	var providerLevels [][]*providerContext
+	for _, nodes := range levels {
+		var level []*providerContext
+		for _, node := range nodes {
+			level = append(level, providersMap[node.Name]...)
+		}
+		resolved = append(resolved, nodes...)
+		providers = append(providers, level...)
+		providerLevels = append(providerLevels, level)
 	}
-	h.providers = providers
+	h.lock.Lock()
+	h.providers, h.levels, h.graph = providers, providerLevels, resolved
+	h.lock.Unlock()
 	return resolved, nil
 }
 
+// dependencyProviders return the names of providers which the dependency of pc is resolved to,
+// only declared dependencies and multi-binding fields are resolved to all providers of service.
+func (h *Hub) dependencyProviders(pc *providerContext, dep *dependency, service string) ([]string, error) {
+	if dep.source == dependencySourceDefine || dep.source == dependencySourceDecorator ||
+		(dep.multi && !strings.Contains(service, "@")) {
+		return findServiceProviders(h.servicesMap, service), nil
+	}
+	found, err := h.findProvider(newDependencyContext(service, pc.name, pc.label, nil, dep.tags))
+	if err != nil || found == nil {
+		return nil, err
+	}
+	if dep.lazy {
+		if typ := found.instanceType(); typ != nil && !typ.AssignableTo(dep.typ) {
+			return nil, fmt.Errorf("service %s of provider %s is %s, not implement %s of lazy field %s", service, found.key, typ, dep.typ, dep.field)
+		}
+	}
+	return []string{found.name}, nil
+}
+
+// findServiceProviders find the names of providers which provide the service, service can be with @label.
+func findServiceProviders(services map[string][]*providerContext, service string) (names []string) {
+	name := service
+	var label string
+	idx := strings.Index(service, "@")
+	if idx > 0 {
+		name, label = service[0:idx], service[idx+1:]
+	}
+	for _, dep := range services[name] {
+		if len(label) > 0 {
+			if dep.label == label {
+				return []string{dep.name}
+			}
+		} else if len(names) <= 0 || names[len(names)-1] != dep.name {
+			names = append(names, dep.name)
+		}
+	}
+	if len(label) > 0 {
+		return nil
+	}
+	return names
+}
+
 // StartWithSignal .
 func (h *Hub) StartWithSignal() error {
 	sigs := []os.Signal{syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT}
+	if h.reloadConfig {
+		sigs = sigs[1:] // SIGHUP reloads config
+		done := make(chan struct{})
+		defer close(done)
+		go h.watchConfig(done)
+	}
 	h.logger.Infof("signals to quit: %v", sigs)
 	return h.Start(signalx.Notify(sigs...))
 }
@@ -214,67 +395,116 @@ func (h *Hub) Start(closer ...<-chan os.Signal) (err error) {
 	ctx := h.ctx
 	ch := make(chan error, len(h.providers))
 	var num int
-	var allTasks sync.Map
+	if h.orderedStart {
+		for _, item := range h.providers {
+			item.ready = make(chan struct{})
+		}
+	}
 	for _, item := range h.providers {
 		key := item.key
 		if key != item.name {
 			key = fmt.Sprintf("%s (%s)", item.key, item.name)
 		}
+		item.runCtx, item.runCancel = context.WithCancel(ctx)
+		ctx, wg := item.runCtx, &item.wg
+		var gate *startGate
+		if h.orderedStart {
+			gate = &startGate{ch: make(chan struct{})}
+			num++
+			wg.Add(1)
+			go func(key string, item *providerContext, gate *startGate) {
+				err := h.waitDependencies(ctx, item)
+				gate.err = err
+				close(gate.ch)
+				if err == nil {
+					err = item.waitReady(ctx, key, h.readyTimeout)
+				}
+				item.readyErr = err
+				close(item.ready)
+				if err != nil {
+					if ctx.Err() != nil {
+						err = nil // provider is closing
+					} else {
+						h.logger.Errorf("provider %s is not ready: %s", key, err)
+					}
+				} else {
+					h.logger.Infof("provider %s is ready", key)
+				}
+				wg.Done()
+				ch <- err
+			}(key, item, gate)
+		}
 		if runner, ok := item.provider.(ProviderRunner); ok {
 			num++
-			h.wg.Add(1)
+			wg.Add(1)
 			go func(key string, provider ProviderRunner) {
+				if !gate.wait() {
+					wg.Done()
+					ch <- nil
+					return
+				}
 				taskKey := key + ".Start+Close"
-				allTasks.Store(taskKey, true)
-				defer allTasks.Delete(taskKey)
+				h.tasks.Store(taskKey, true)
+				defer h.tasks.Delete(taskKey)
 				h.logger.Infof("provider %s starting ...", key)
-				err := provider.Start()
+				start := func(context.Context) error { return provider.Start() }
+				err := h.recoverFunc(key, "Start", start)(ctx)
 				if err != nil {
 					h.logger.Errorf("failed to start provider %s: %s", key, err)
 				} else {
 					h.logger.Infof("provider %s closed", key)
 				}
-				h.wg.Done()
+				wg.Done()
 				ch <- err
 			}(key, runner)
 		}
 		if runner, ok := item.provider.(ProviderRunnerWithContext); ok {
 			num++
-			h.wg.Add(1)
-			go func(key string, provider ProviderRunnerWithContext) {
+			wg.Add(1)
+			go func(key string, provider ProviderRunnerWithContext, restart restartOptions) {
+				if !gate.wait() {
+					wg.Done()
+					ch <- nil
+					return
+				}
 				taskKey := key + ".Run"
-				allTasks.Store(taskKey, true)
-				defer allTasks.Delete(taskKey)
+				h.tasks.Store(taskKey, true)
+				defer h.tasks.Delete(taskKey)
 				h.logger.Infof("provider %s running ...", key)
-				err := provider.Run(ctx)
+				err := h.runWithRestart(ctx, "provider "+key+" Run", restart, h.recoverFunc(key, "Run", provider.Run))
 				if err != nil {
 					h.logger.Errorf("failed to run provider %s: %s", key, err)
 				} else {
 					h.logger.Infof("provider %s Run exit", key)
 				}
-				h.wg.Done()
+				wg.Done()
 				ch <- err
-			}(key, runner)
+			}(key, runner, item.restart)
 		}
 		for i, t := range item.tasks {
 			num++
-			h.wg.Add(1)
+			wg.Add(1)
 			go func(key string, i int, t task) {
+				if !gate.wait() {
+					wg.Done()
+					ch <- nil
+					return
+				}
 				tname := t.name
 				if len(tname) <= 0 {
 					tname = strconv.Itoa(i + 1)
 				}
 				taskKey := key + ".Task(" + tname + ")"
-				allTasks.Store(taskKey, true)
-				defer allTasks.Delete(taskKey)
+				h.tasks.Store(taskKey, true)
+				defer h.tasks.Delete(taskKey)
 				h.logger.Infof("provider %s task(%s) running ...", key, tname)
-				err := t.fn(ctx)
+				err := h.runWithRestart(ctx, "provider "+key+" task("+tname+")", t.restart, h.recoverFunc(key, "task("+tname+")", t.fn))
 				if err != nil {
 					h.logger.Errorf("failed to run provider %s task(%s): %s", key, tname, err)
 				} else {
 					h.logger.Infof("provider %s task(%s) exit", key, tname)
 				}
-				h.wg.Done()
+				wg.Done()
 				ch <- err
 			}(key, i, t)
 		}
@@ -310,7 +540,7 @@ func (h *Hub) Start(closer ...<-chan os.Signal) (err error) {
 				select {
 				case <-time.After(timeout):
 					var keys []string
-					allTasks.Range(func(key, value interface{}) bool {
+					h.tasks.Range(func(key, value interface{}) bool {
 						keys = append(keys, key.(string))
 						return true
 					})
@@ -353,6 +583,40 @@ func (h *Hub) Start(closer ...<-chan os.Signal) (err error) {
 	return err
 }
 
+// startGate blocks the runners of a provider until its dependencies are ready.
+type startGate struct {
+	ch  chan struct{}
+	err error
+}
+
+// wait returns false if the runner must not be started.
+func (g *startGate) wait() bool {
+	if g == nil {
+		return true
+	}
+	<-g.ch
+	return g.err == nil
+}
+
+func (h *Hub) waitDependencies(ctx context.Context, pc *providerContext) error {
+	for _, name := range pc.depends {
+		for _, dep := range h.providersMap[name] {
+			if dep.ready == nil {
+				continue
+			}
+			select {
+			case <-dep.ready:
+				if dep.readyErr != nil {
+					return fmt.Errorf("dependency %s is not ready: %w", dep.key, dep.readyErr)
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
 // Close .
 func (h *Hub) Close() error {
 	h.lock.Lock()
@@ -362,21 +626,56 @@ func (h *Hub) Close() error {
 	}
 	var errs errorx.Errors
 	for i := len(h.providers) - 1; i >= 0; i-- {
-		if runner, ok := h.providers[i].provider.(ProviderRunner); ok {
-			err := runner.Close()
-			if err != nil {
-				errs = append(errs, err)
-			}
+		err := h.closeProvider(h.providers[i])
+		if err != nil {
+			errs = append(errs, err)
 		}
 	}
 	h.cancel()
-	h.wg.Wait()
 	h.started = false
 	h.ctx, h.cancel = context.WithCancel(context.Background())
 	h.lock.Unlock()
 	return errs.MaybeUnwrap()
 }
 
+// closeProvider close the provider and wait its Start, Run and tasks to exit,
+// providers are closed in reverse dependency order, so its dependents have been stopped.
+func (h *Hub) closeProvider(pc *providerContext) error {
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		if runner, ok := pc.provider.(ProviderRunner); ok {
+			err = runner.Close()
+		}
+		if pc.runCancel != nil {
+			pc.runCancel()
+		}
+		pc.wg.Wait()
+		done <- err
+	}()
+	if pc.exitTimeout <= 0 {
+		return <-done
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(pc.exitTimeout):
+		key := pc.key
+		if key != pc.name {
+			key = fmt.Sprintf("%s (%s)", pc.key, pc.name)
+		}
+		var tasks []string
+		h.tasks.Range(func(k, v interface{}) bool {
+			if strings.HasPrefix(k.(string), key+".") {
+				tasks = append(tasks, k.(string))
+			}
+			return true
+		})
+		h.logger.Errorf("provider %s exit timeout after %s, running: [%s]", key, pc.exitTimeout, strings.Join(tasks, ","))
+		return fmt.Errorf("provider %s exit timeout after %s", key, pc.exitTimeout)
+	}
+}
+
 // ForeachServices .
 func (h *Hub) ForeachServices(fn func(service string) bool) {
 	for key := range h.servicesMap {
@@ -393,86 +692,139 @@ func (h *Hub) IsServiceExist(service string) bool {
 
 // Service .
 func (h *Hub) Service(name string, options ...interface{}) interface{} {
-	return h.getService(newDependencyContext(
+	instance, err := h.getService(newDependencyContext(
 		name,
 		"",
 		"",
 		nil,
 		reflect.StructTag(""),
 	), options...)
+	if err != nil {
+		h.logger.Errorf("failed to get service %s: %s", name, err)
+	}
+	return instance
+}
+
+func (h *Hub) getService(dc DependencyContext, options ...interface{}) (interface{}, error) {
+	if d, ok := dc.(*dependencyContext); ok && d.hub != h {
+		hdc := *d
+		hdc.hub = h
+		dc = &hdc
+	}
+	dc = h.bindDependency(dc)
+	pc, err := h.findProvider(dc)
+	if err != nil || pc == nil {
+		return nil, err
+	}
+	if pc.lifetime == LifetimeScoped {
+		if d, ok := dc.(*dependencyContext); !ok || d.scope == nil {
+			return nil, fmt.Errorf("provider %s is scoped, lookup it through Scope.Service", pc.name)
+		}
+	}
+	return h.decorate(pc, pc.provide(dc, options...), dc), nil
 }
 
-func (h *Hub) getService(dc DependencyContext, options ...interface{}) (instance interface{}) {
-	var pc *providerContext
+// findProvider find the provider which a single-value dependency is resolved to,
+// it returns an error if the dependency can be resolved to providers of different names.
+func (h *Hub) findProvider(dc DependencyContext) (*providerContext, error) {
+	var (
+		providers []*providerContext
+		what      string
+	)
 	if len(dc.Service()) > 0 {
-		if providers, ok := h.servicesMap[dc.Service()]; ok {
-			if len(providers) > 0 {
-				if label := dc.Label(); len(label) > 0 {
-					for _, item := range providers {
-						if item.label == label {
-							pc = item
-							break
-						}
-					}
-				} else {
-					follow, _ := strconv.ParseBool(dc.Tags().Get("follow-label"))
-					if follow {
-						if callerLabel := dc.CallerLabel(); len(callerLabel) > 0 {
-							for _, item := range providers {
-								if item.label == callerLabel {
-									pc = item
-									break
-								}
-							}
-						}
-					}
-					if pc == nil {
-						for _, item := range providers {
-							if len(item.label) <= 0 {
-								pc = item
-								break
-							}
-						}
-					}
-					if pc == nil && len(providers) > 0 {
-						pc = providers[0]
-					}
-				}
-			}
+		providers = h.servicesMap[dc.Service()]
+		what = "service " + dc.Service()
+		if label := dc.Label(); len(label) > 0 {
+			return uniqueProvider(what+"@"+label, providersWithLabel(providers, label))
 		}
 	} else if dc.Type() != nil {
-		providers := h.servicesTypes[dc.Type()]
-		follow, _ := strconv.ParseBool(dc.Tags().Get("follow-label"))
-		if follow {
-			if callerLabel := dc.CallerLabel(); len(callerLabel) > 0 {
-				for _, item := range providers {
-					if item.label == callerLabel {
-						pc = item
-						break
-					}
-				}
-			}
+		var err error
+		providers, err = h.findTypeProviders(dc.Type(), isAutowired(dc.Tags()))
+		if err != nil {
+			return nil, err
 		}
-		if pc == nil {
-			for _, item := range providers {
-				if len(item.label) <= 0 {
-					pc = item
-					break
-				}
+		what = "type " + dc.Type().String()
+	}
+	if len(providers) <= 0 {
+		return nil, nil
+	}
+	follow, _ := strconv.ParseBool(dc.Tags().Get("follow-label"))
+	if follow {
+		if callerLabel := dc.CallerLabel(); len(callerLabel) > 0 {
+			if list := providersWithLabel(providers, callerLabel); len(list) > 0 {
+				return uniqueProvider(what+"@"+callerLabel, list)
 			}
 		}
-		if pc == nil && len(providers) > 0 {
-			pc = providers[0]
+	}
+	if _, err := uniqueProvider(what, providers); err != nil {
+		return nil, err
+	}
+	for _, item := range providers {
+		if len(item.label) <= 0 {
+			return item, nil
 		}
 	}
-	if pc != nil {
-		provider := pc.provider
-		if prod, ok := provider.(DependencyProvider); ok {
-			return prod.Provide(dc, options...)
+	return providers[0], nil
+}
+
+func providersWithLabel(providers []*providerContext, label string) (list []*providerContext) {
+	for _, item := range providers {
+		if item.label == label {
+			list = append(list, item)
 		}
-		return provider
 	}
-	return nil
+	return list
+}
+
+// uniqueProvider return the first of providers, or an error if they have different names.
+func uniqueProvider(what string, providers []*providerContext) (*providerContext, error) {
+	if len(providers) <= 0 {
+		return nil, nil
+	}
+	for _, item := range providers[1:] {
+		if item.name == providers[0].name {
+			continue
+		}
+		candidates := make([]string, 0, len(providers))
+		for _, pc := range providers {
+			candidates = append(candidates, pc.key)
+		}
+		sort.Strings(candidates)
+		return nil, fmt.Errorf("ambiguous providers of %s, candidates: %s", what, strings.Join(candidates, ", "))
+	}
+	return providers[0], nil
+}
+
+// findTypeProviders find the providers of type, the providers declared the exact type are preferred,
+// otherwise the unique provider which declared a type assignable to typ, such as an implementation of interface,
+// if assignable is true. It is false for fields without autowired tag, which are not dependencies always.
+func (h *Hub) findTypeProviders(typ reflect.Type, assignable bool) ([]*providerContext, error) {
+	if providers, ok := h.servicesTypes[typ]; ok || !assignable {
+		return providers, nil
+	}
+	if typ.Kind() == reflect.Interface && typ.NumMethod() <= 0 {
+		return nil, nil // every type is assignable to empty interface
+	}
+	var (
+		providers  []*providerContext
+		names      = make(map[string]bool)
+		candidates []string
+	)
+	for t, list := range h.servicesTypes {
+		if !t.AssignableTo(typ) {
+			continue
+		}
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", list[0].name, t))
+		if !names[list[0].name] {
+			names[list[0].name] = true
+			providers = list
+		}
+	}
+	if len(names) > 1 {
+		sort.Strings(candidates)
+		return nil, fmt.Errorf("ambiguous providers of type %s, candidates: %s", typ, strings.Join(candidates, ", "))
+	}
+	return providers, nil
 }
 
 // Provider .
@@ -498,11 +850,13 @@ func (h *Hub) Provider(name string) interface{} {
 
 // RunOptions .
 type RunOptions struct {
-	Name       string
-	ConfigFile string
-	Content    interface{}
-	Format     string
-	Args       []string
+	Name        string
+	ConfigFile  string
+	ConfigFiles []string // loaded after ConfigFile in order, later files override earlier ones, see mergeConfig
+	Profile     string   // load <name>.<profile>.<ext> after each config file
+	Content     interface{}
+	Format      string
+	Args        []string
 }
 
 // RunWithOptions .
@@ -530,23 +884,23 @@ func (h *Hub) RunWithOptions(opts *RunOptions) {
 	if len(opts.Format) > 0 {
 		format = opts.Format
 	}
-	cfgmap := make(map[string]interface{})
+	content := make(map[string]interface{})
 	if opts.Content != nil {
-		var reader io.Reader
+		var byts []byte
 		switch val := opts.Content.(type) {
 		case map[string]interface{}:
-			cfgmap = val
+			content = val
 		case string:
-			reader = strings.NewReader(val)
+			byts = []byte(val)
 		case []byte:
-			reader = bytes.NewReader(val)
+			byts = val
 		default:
 			err = fmt.Errorf("invalid config content type")
 			h.logger.Error(err)
 			return
 		}
-		if reader != nil {
-			err = config.UnmarshalToMap(reader, format, cfgmap)
+		if byts != nil {
+			err = unmarshalConfig(byts, format, content)
 			if err != nil {
 				h.logger.Errorf("failed to parse %s config: %s", format, err)
 				return
@@ -554,13 +908,18 @@ func (h *Hub) RunWithOptions(opts *RunOptions) {
 		}
 	}
 
-	cfgfile := opts.ConfigFile
-	if len(cfgmap) <= 0 && len(opts.ConfigFile) <= 0 {
-		cfgfile = name + "." + format
+	var cfgfiles []string
+	if len(opts.ConfigFile) > 0 {
+		cfgfiles = append(cfgfiles, opts.ConfigFile)
+	}
+	cfgfiles = append(cfgfiles, opts.ConfigFiles...)
+	if len(content) <= 0 && len(cfgfiles) <= 0 {
+		cfgfiles = []string{name + "." + format}
 	}
 
 	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
-	flags.StringP("config", "c", cfgfile, "config file to load providers")
+	flags.StringSliceP("config", "c", cfgfiles, "config files to load providers, later files override earlier ones")
+	flags.String("profile", opts.Profile, "profile of config, load <name>.<profile>.<ext> after each config file")
 	flags.String("log.level", "", "setup log level")
 	flags.DurationVar(&h.exitTimeout, "exit.timeout", 30*time.Second, "setup exit level")
 	flags.Parse(opts.Args)
@@ -570,13 +929,14 @@ func (h *Hub) RunWithOptions(opts *RunOptions) {
 		h.logger.SetLevel(level)
 	}
 
-	cfgfile, _ = flags.GetString("config")
-	if len(cfgfile) > 0 {
-		cfgmap, err = h.loadConfig(cfgfile, cfgmap)
-		if err != nil {
-			return
-		}
+	cfgfiles, _ = flags.GetStringSlice("config")
+	profile, _ := flags.GetString("profile")
+	h.configLoader = &configLoader{content: content, files: cfgfiles, profile: profile}
+	cfgmap, sources, err := h.configLoader.load(h)
+	if err != nil {
+		return
 	}
+	h.configSources = sources
 	err = h.Init(cfgmap, flags, opts.Args)
 	if err != nil {
 		return
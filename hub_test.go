@@ -2,13 +2,18 @@ package servicehub
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/recallsong/go-utils/errorx"
+	graph "github.com/recallsong/servicehub/dependency-graph"
 	"github.com/recallsong/servicehub/logs"
+	"github.com/spf13/pflag"
 )
 
 type testBaseProvider struct{}
@@ -379,7 +384,7 @@ func TestHub(t *testing.T) {
 			}
 			wg.Wait()
 			for _, item := range list {
-				delete(serviceProviders, item.d.name)
+				defaultRegistry.Unregister(item.d.name)
 			}
 		})
 	}
@@ -460,7 +465,359 @@ func TestHub_Dependencies(t *testing.T) {
 				t.Errorf("Hub.Close() = %v, want nil", err)
 			}
 			for _, p := range tt.providers {
-				delete(serviceProviders, p.name)
+				defaultRegistry.Unregister(p.name)
+			}
+		})
+	}
+}
+
+type testReadyProvider struct {
+	testStartProvider
+	ready chan error
+}
+
+func (p *testReadyProvider) Ready(ctx context.Context) error {
+	select {
+	case err := <-p.ready:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestHub_OrderedStart(t *testing.T) {
+	tests := []struct {
+		name    string
+		ready   func(p *testReadyProvider)
+		wantErr bool
+	}{
+		{
+			name: "ready",
+			ready: func(p *testReadyProvider) {
+				p.ready <- nil
+			},
+		},
+		{
+			name: "not ready",
+			ready: func(p *testReadyProvider) {
+				p.ready <- fmt.Errorf("connection refused")
+			},
+			wantErr: true,
+		},
+		{
+			name:    "ready timeout",
+			ready:   func(p *testReadyProvider) {},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p1 := &testReadyProvider{
+				testStartProvider: testStartProvider{make(chan interface{}, 1), make(chan interface{}, 1)},
+				ready:             make(chan error, 1),
+			}
+			p2 := &testStartProvider{make(chan interface{}, 1), make(chan interface{}, 1)}
+			providers := []testDefine{
+				testRegister("test1", nil, nil, func() Provider { return p1 }),
+				testRegister("test2", []string{"test1"}, nil, func() Provider { return p2 }),
+			}
+			for _, p := range providers {
+				Register(p.name, p.spec)
+			}
+			defer func() {
+				for _, p := range providers {
+					defaultRegistry.Unregister(p.name)
+				}
+			}()
+
+			hub := New(WithOrderedStart(100 * time.Millisecond))
+			err := hub.Init(map[string]interface{}{
+				testProviderName("test1"): nil,
+				testProviderName("test2"): nil,
+			}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+			if err != nil {
+				t.Fatalf("Hub.Init() = %v, want nil", err)
+			}
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- hub.Start()
+			}()
+			<-p1.started
+			select {
+			case <-p2.started:
+				t.Fatalf("provider test2 started before test1 is ready")
+			case <-time.After(20 * time.Millisecond):
+			}
+			tt.ready(p1)
+			if tt.wantErr {
+				if err := <-errCh; err == nil {
+					t.Errorf("Hub.Start() = nil, want err != nil")
+				}
+				select {
+				case <-p2.started:
+					t.Errorf("provider test2 started, but test1 is not ready")
+				default:
+				}
+				return
+			}
+			<-p2.started
+			if err := hub.Close(); err != nil {
+				t.Errorf("Hub.Close() = %v, want nil", err)
+			}
+			if err := <-errCh; err != nil {
+				t.Errorf("Hub.Start() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+type testInitFuncProvider struct {
+	init func(ctx Context) error
+}
+
+func (p *testInitFuncProvider) Init(ctx Context) error { return p.init(ctx) }
+
+func TestHub_ParallelInit(t *testing.T) {
+	// test1 and test2 only finish Init after both of them are initializing
+	barrier := func(entered chan interface{}, other chan interface{}) func(ctx Context) error {
+		return func(ctx Context) error {
+			entered <- nil
+			select {
+			case <-other:
+				return nil
+			case <-time.After(time.Second):
+				return fmt.Errorf("provider %s is not initialized in parallel", ctx.Key())
+			}
+		}
+	}
+	ch1, ch2 := make(chan interface{}, 1), make(chan interface{}, 1)
+	var test3Init bool
+	tests := []struct {
+		name       string
+		providers  []testDefine
+		wantErrNum int
+	}{
+		{
+			name: "parallel",
+			providers: []testDefine{
+				testRegister("test1", nil, nil, func() Provider { return &testInitFuncProvider{barrier(ch1, ch2)} }),
+				testRegister("test2", nil, nil, func() Provider { return &testInitFuncProvider{barrier(ch2, ch1)} }),
+				testRegister("test3", []string{"test1", "test2"}, nil, func() Provider {
+					return &testInitFuncProvider{func(ctx Context) error {
+						test3Init = true
+						return nil
+					}}
+				}),
+			},
+		},
+		{
+			name: "errors",
+			providers: []testDefine{
+				testRegister("test1", nil, nil, func() Provider {
+					return &testInitFuncProvider{func(ctx Context) error { return fmt.Errorf("error 1") }}
+				}),
+				testRegister("test2", nil, nil, func() Provider {
+					return &testInitFuncProvider{func(ctx Context) error { return fmt.Errorf("error 2") }}
+				}),
+				testRegister("test3", []string{"test1", "test2"}, nil, func() Provider {
+					return &testInitFuncProvider{func(ctx Context) error {
+						test3Init = true
+						return nil
+					}}
+				}),
+			},
+			wantErrNum: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test3Init = false
+			cfg := map[string]interface{}{}
+			for _, p := range tt.providers {
+				Register(p.name, p.spec)
+				cfg[p.name] = nil
+			}
+			defer func() {
+				for _, p := range tt.providers {
+					defaultRegistry.Unregister(p.name)
+				}
+			}()
+			hub := New(WithParallelInit())
+			err := hub.Init(cfg, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+			if tt.wantErrNum <= 0 {
+				if err != nil {
+					t.Fatalf("Hub.Init() = %v, want nil", err)
+				}
+				if !test3Init {
+					t.Errorf("provider test3 is not initialized")
+				}
+				return
+			}
+			errs, ok := err.(errorx.Errors)
+			if !ok || len(errs) != tt.wantErrNum {
+				t.Fatalf("Hub.Init() = %v, want %d errors", err, tt.wantErrNum)
+			}
+			if test3Init {
+				t.Errorf("provider test3 is initialized, but its dependencies failed")
+			}
+		})
+	}
+}
+
+type testRunFuncProvider struct {
+	run func(ctx context.Context) error
+}
+
+func (p *testRunFuncProvider) Run(ctx context.Context) error { return p.run(ctx) }
+
+func TestHub_Close(t *testing.T) {
+	running := make(chan string, 2)
+	exited := make(chan string, 2)
+	runFunc := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			running <- name
+			<-ctx.Done()
+			exited <- name
+			return nil
+		}
+	}
+	providers := []testDefine{
+		testRegister("test1", nil, nil, func() Provider { return &testRunFuncProvider{runFunc("test1")} }),
+		testRegister("test2", []string{"test1"}, nil, func() Provider { return &testRunFuncProvider{runFunc("test2")} }),
+		testRegister("test3", nil, nil, func() Provider {
+			return &testRunFuncProvider{func(ctx context.Context) error {
+				running <- "test3"
+				<-ctx.Done()
+				time.Sleep(time.Second)
+				return nil
+			}}
+		}),
+	}
+	for _, p := range providers {
+		Register(p.name, p.spec)
+	}
+	defer func() {
+		for _, p := range providers {
+			defaultRegistry.Unregister(p.name)
+		}
+	}()
+
+	hub := New()
+	err := hub.Init(map[string]interface{}{
+		testProviderName("test1"): nil,
+		testProviderName("test2"): nil,
+		testProviderName("test3"): map[string]interface{}{
+			"_exit_timeout": "50ms",
+		},
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	go hub.Start()
+	for i := 0; i < 3; i++ {
+		<-running
+	}
+	err = hub.Close()
+	if err == nil || !strings.Contains(err.Error(), testProviderName("test3")) {
+		t.Errorf("Hub.Close() = %v, want exit timeout error of %s", err, testProviderName("test3"))
+	}
+	if first, second := <-exited, <-exited; first != "test2" || second != "test1" {
+		t.Errorf("providers exit order = [%s %s], want [test2 test1]", first, second)
+	}
+}
+
+func TestHub_CircularDependency(t *testing.T) {
+	type provider1 struct {
+		Test2 interface{} `autowired:"test2"`
+	}
+	type provider2 struct {
+		Test1 interface{} `autowired:"test1"`
+	}
+	providers := []testDefine{
+		testRegister("test1", nil, nil, func() Provider { return &provider1{} }),
+		testRegister("test2", nil, nil, func() Provider { return &provider2{} }),
+	}
+	for _, p := range providers {
+		Register(p.name, p.spec)
+	}
+	defer func() {
+		for _, p := range providers {
+			defaultRegistry.Unregister(p.name)
+		}
+	}()
+	hub := New()
+	err := hub.Init(map[string]interface{}{
+		testProviderName("test1"): nil,
+		testProviderName("test2"): nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	var cerr *graph.CycleError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("Hub.Init() = %v, want *graph.CycleError", err)
+	}
+	if len(cerr.Cycles) != 1 || len(cerr.Cycles[0].Nodes) != 2 {
+		t.Fatalf("CycleError.Cycles = %v, want 1 cycle with 2 nodes", cerr.Cycles)
+	}
+	for _, field := range []string{"Test1", "Test2"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Hub.Init() = %q, want field %s in message", err, field)
+		}
+	}
+}
+
+func TestHub_AutowiredByAssignableType(t *testing.T) {
+	type consumer struct {
+		Store    testStore `autowired:""`
+		Untagged testStore
+		Any      interface{} `autowired:""`
+	}
+	tests := []struct {
+		name    string
+		types   []reflect.Type
+		wantErr bool
+	}{
+		{
+			name:  "unique",
+			types: []reflect.Type{reflect.TypeOf((*testStoreImpl)(nil))},
+		},
+		{
+			name:    "ambiguous",
+			types:   []reflect.Type{reflect.TypeOf((*testStoreImpl)(nil)), reflect.TypeOf((*testCtorDBImpl)(nil))},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			cfg := map[string]interface{}{"consumer": nil}
+			for i, typ := range tt.types {
+				name, typ := fmt.Sprintf("provider%d", i), typ
+				r.Register(name, &Spec{
+					Types:   []reflect.Type{typ},
+					Creator: func() Provider { return reflect.New(typ.Elem()).Interface() },
+				})
+				cfg[name] = nil
+			}
+			c := &consumer{}
+			r.Register("consumer", &Spec{Creator: func() Provider { return c }})
+			hub := New(WithRegistry(r))
+			err := hub.Init(cfg, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "provider0") || !strings.Contains(err.Error(), "provider1") {
+					t.Errorf("Hub.Init() = %v, want ambiguous error with candidates", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Hub.Init() = %v, want nil", err)
+			}
+			if c.Store == nil || c.Store != hub.Provider("provider0") {
+				t.Errorf("consumer.Store is not autowired")
+			}
+			if c.Untagged != nil {
+				t.Errorf("consumer.Untagged = %v, want nil without autowired tag", c.Untagged)
+			}
+			if c.Any != nil {
+				t.Errorf("consumer.Any = %v, want nil", c.Any)
 			}
 		})
 	}
@@ -541,3 +898,30 @@ func Test_boolTagValue(t *testing.T) {
 		})
 	}
 }
+
+func Test_graphFormat(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: nil, want: ""},
+		{args: []string{"--graph"}, want: "text"},
+		{args: []string{"-g"}, want: "text"},
+		{args: []string{"--graph=dot"}, want: "dot"},
+		{args: []string{"--graph=true"}, want: "text"},
+		{args: []string{"--graph=false"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.StringP("graph", "g", "", "")
+			flags.Lookup("graph").NoOptDefVal = "text"
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if got := graphFormat(flags); got != tt.want {
+				t.Errorf("graphFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package servicehub
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envVarRegexp matches ${VAR}, ${VAR:-default}, ${VAR:default} and $VAR.
+var envVarRegexp = regexp.MustCompile(`\$\{(\w+)(:-?([^}]*))?\}|\$(\w+)`)
+
+// interpolateConfig replace the environment variables in keys and string values of config,
+// ${VAR} is required, ${VAR:-default} uses default if VAR is not set, and $VAR is kept if VAR is not set.
+func interpolateConfig(cfg map[string]interface{}) (map[string]interface{}, error) {
+	var missing []string
+	val := interpolateValue(cfg, "", &missing)
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("environment variables are required by config: %s", strings.Join(missing, ", "))
+	}
+	result, _ := val.(map[string]interface{})
+	return result, nil
+}
+
+func interpolateValue(val interface{}, path string, missing *[]string) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			key = interpolateString(key, path, missing)
+			result[key] = interpolateValue(item, joinConfigPath(path, key), missing)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = interpolateValue(item, joinConfigPath(path, fmt.Sprint(i)), missing)
+		}
+		return result
+	case string:
+		return interpolateString(v, path, missing)
+	}
+	return val
+}
+
+func interpolateString(s, path string, missing *[]string) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+	return envVarRegexp.ReplaceAllStringFunc(s, func(expr string) string {
+		m := envVarRegexp.FindStringSubmatch(expr)
+		if len(m[4]) > 0 {
+			if val, ok := os.LookupEnv(m[4]); ok {
+				return val
+			}
+			return expr
+		}
+		if val, ok := os.LookupEnv(m[1]); ok {
+			return val
+		}
+		if len(m[2]) > 0 {
+			return m[3]
+		}
+		where := path
+		if len(where) <= 0 {
+			where = s
+		}
+		*missing = append(*missing, fmt.Sprintf("%s (at %s)", m[1], where))
+		return ""
+	})
+}
+
+// restoreEnvKeys restore the case of environment variables in the keys of cfg which are lowercased by parser,
+// such as mysql@${DB_LABEL}, by the expressions found in raw content of cfg.
+func restoreEnvKeys(cfg map[string]interface{}, raw []byte) {
+	exprs := make(map[string]string)
+	for _, expr := range envVarRegexp.FindAllString(string(raw), -1) {
+		lower := strings.ToLower(expr)
+		if _, ok := exprs[lower]; !ok && lower != expr {
+			exprs[lower] = expr
+		}
+	}
+	if len(exprs) > 0 {
+		restoreKeys(cfg, exprs)
+	}
+}
+
+func restoreKeys(val interface{}, exprs map[string]string) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		renames := make(map[string]string)
+		for key, item := range v {
+			restoreKeys(item, exprs)
+			if !strings.Contains(key, "$") {
+				continue
+			}
+			restored := envVarRegexp.ReplaceAllStringFunc(key, func(expr string) string {
+				if orig, ok := exprs[expr]; ok {
+					return orig
+				}
+				return expr
+			})
+			if restored != key {
+				renames[key] = restored
+			}
+		}
+		for key, restored := range renames {
+			v[restored] = v[key]
+			delete(v, key)
+		}
+	case []interface{}:
+		for _, item := range v {
+			restoreKeys(item, exprs)
+		}
+	}
+}
+
+func joinConfigPath(path, key string) string {
+	if len(path) <= 0 {
+		return key
+	}
+	return path + "." + key
+}
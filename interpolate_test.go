@@ -0,0 +1,129 @@
+package servicehub
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func Test_interpolateConfig(t *testing.T) {
+	os.Setenv("TEST_INTERPOLATE_HOST", "db.local")
+	os.Setenv("TEST_INTERPOLATE_LABEL", "a")
+	defer os.Unsetenv("TEST_INTERPOLATE_HOST")
+	defer os.Unsetenv("TEST_INTERPOLATE_LABEL")
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "values",
+			cfg: map[string]interface{}{
+				"mysql": map[string]interface{}{
+					"host":  "${TEST_INTERPOLATE_HOST}",
+					"port":  "${TEST_INTERPOLATE_PORT:-3306}",
+					"addr":  "tcp://${TEST_INTERPOLATE_HOST}:${TEST_INTERPOLATE_PORT:-3306}",
+					"user":  "$TEST_INTERPOLATE_USER",
+					"hosts": []interface{}{"$TEST_INTERPOLATE_HOST", 1},
+				},
+			},
+			want: map[string]interface{}{
+				"mysql": map[string]interface{}{
+					"host":  "db.local",
+					"port":  "3306",
+					"addr":  "tcp://db.local:3306",
+					"user":  "$TEST_INTERPOLATE_USER",
+					"hosts": []interface{}{"db.local", 1},
+				},
+			},
+		},
+		{
+			name: "keys",
+			cfg: map[string]interface{}{
+				"mysql@${TEST_INTERPOLATE_LABEL}": map[string]interface{}{
+					"_enable": "${TEST_INTERPOLATE_ENABLE:-false}",
+				},
+			},
+			want: map[string]interface{}{
+				"mysql@a": map[string]interface{}{
+					"_enable": "false",
+				},
+			},
+		},
+		{
+			name: "required",
+			cfg: map[string]interface{}{
+				"mysql": map[string]interface{}{
+					"password": "${TEST_INTERPOLATE_PASSWORD}",
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interpolateConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("interpolateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("interpolateConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHub_InterpolateEnable(t *testing.T) {
+	r := NewRegistry()
+	r.Register("test-provider", &Spec{
+		Services: []string{"test"},
+		Creator:  func() Provider { return "test" },
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"test-provider": map[string]interface{}{
+			"_enable": "${TEST_INTERPOLATE_ENABLE:-false}",
+		},
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	if hub.Service("test") != nil {
+		t.Errorf("test-provider is loaded, want disabled by _enable")
+	}
+}
+
+func TestHub_InterpolateFileKeys(t *testing.T) {
+	os.Setenv("TEST_INTERPOLATE_LABEL", "Primary")
+	defer os.Unsetenv("TEST_INTERPOLATE_LABEL")
+	file := filepath.Join(t.TempDir(), "app.yaml")
+	content := "test-provider@${TEST_INTERPOLATE_LABEL}:\n    Addr: ${TEST_INTERPOLATE_ADDR:-:8080}\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRegistry()
+	r.Register("test-provider", &Spec{
+		Services: []string{"test"},
+		Creator:  func() Provider { return "test" },
+	})
+	hub := New(WithRegistry(r))
+	cfg, err := hub.loadConfigFiles([]string{file}, "", make(map[string]interface{}), make(map[string]string))
+	if err != nil {
+		t.Fatalf("Hub.loadConfigFiles() = %v, want nil", err)
+	}
+	err = hub.Init(cfg, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	if hub.Provider("test-provider@Primary") == nil {
+		t.Errorf("test-provider@Primary is not loaded")
+	}
+	want := map[string]interface{}{"addr": ":8080"}
+	if got := hub.config["test-provider@Primary"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("config of test-provider@Primary = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package servicehub
+
+import (
+	"sort"
+
+	graph "github.com/recallsong/servicehub/dependency-graph"
+)
+
+// ProviderInfo describes a loaded provider.
+type ProviderInfo struct {
+	Key          string      `json:"key"`
+	Name         string      `json:"name"`
+	Label        string      `json:"label,omitempty"`
+	Services     []string    `json:"services,omitempty"`
+	Dependencies []string    `json:"dependencies,omitempty"`
+	Lifetime     Lifetime    `json:"lifetime,omitempty"`
+	Config       interface{} `json:"config,omitempty"`
+}
+
+// ProviderInfos return the loaded providers in dependency order.
+func (h *Hub) ProviderInfos() []*ProviderInfo {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	var list []*ProviderInfo
+	for _, pc := range h.providers {
+		info := &ProviderInfo{
+			Key:          pc.key,
+			Name:         pc.name,
+			Label:        pc.label,
+			Dependencies: pc.depends,
+			Lifetime:     pc.lifetime,
+			Config:       pc.cfg,
+		}
+		if ps, ok := pc.define.(ProviderServices); ok {
+			info.Services = ps.Services()
+		}
+		list = append(list, info)
+	}
+	return list
+}
+
+// DependencyGraph return the resolved dependency graph of providers.
+func (h *Hub) DependencyGraph() graph.Graph {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.graph
+}
+
+// RunningTasks return the keys of Start, Run and tasks of providers which are running.
+func (h *Hub) RunningTasks() []string {
+	var keys []string
+	h.tasks.Range(func(key, value interface{}) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,69 @@
+package servicehub
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// lazyType return the service type of a field tagged with lazy:"true", the field must be a func() T.
+func lazyType(field reflect.StructField) (reflect.Type, bool, error) {
+	lazy, err := boolTagValue(field.Tag, "lazy", false)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid lazy tag value: %s", err)
+	}
+	if !lazy {
+		return nil, false, nil
+	}
+	typ := field.Type
+	if typ.Kind() != reflect.Func || typ.NumIn() != 0 || typ.NumOut() != 1 {
+		return nil, false, fmt.Errorf("lazy field must be func() T, but got %s", typ)
+	}
+	return typ.Out(0), true, nil
+}
+
+var dependencyProviderType = reflect.TypeOf((*DependencyProvider)(nil)).Elem()
+
+// instanceType return the type of instance which provider provides, nil if it is unknown before init,
+// such as the instances of DependencyProvider.
+func (c *providerContext) instanceType() reflect.Type {
+	var typ reflect.Type
+	if c.provider != nil {
+		typ = reflect.TypeOf(c.provider)
+	} else if c.constructor != nil {
+		typ = c.constructor.fn.Type().Out(0)
+	}
+	if typ == nil || typ.Kind() == reflect.Interface || typ.Implements(dependencyProviderType) {
+		return nil
+	}
+	return typ
+}
+
+// lazyService return a func of typ which resolve the service on first call,
+// it returns zero value and logs the error if the service can not be resolved, or the instance provided on lookup is not assignable.
+func (h *Hub) lazyService(dc DependencyContext, typ reflect.Type) reflect.Value {
+	out := typ.Out(0)
+	var (
+		once   sync.Once
+		result reflect.Value
+	)
+	return reflect.MakeFunc(typ, func([]reflect.Value) []reflect.Value {
+		once.Do(func() {
+			result = reflect.Zero(out)
+			instance, err := h.getService(dc)
+			if err != nil {
+				h.logger.Errorf("failed to get lazy service %q: %s", dc.Key(), err)
+				return
+			}
+			if instance == nil {
+				return
+			}
+			if !reflect.TypeOf(instance).AssignableTo(out) {
+				h.logger.Errorf("service %q is %T, not implement %s", dc.Service(), instance, out)
+				return
+			}
+			result = reflect.ValueOf(instance)
+		})
+		return []reflect.Value{result}
+	})
+}
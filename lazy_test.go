@@ -0,0 +1,126 @@
+package servicehub
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type testLazyA struct {
+	B func() *testLazyB `autowired:"lazy-b" lazy:"true"`
+}
+
+type testLazyB struct {
+	A *testLazyA `autowired:"lazy-a"`
+}
+
+func TestHub_LazyDependency(t *testing.T) {
+	r := NewRegistry()
+	a, b := &testLazyA{}, &testLazyB{}
+	r.Register("lazy-a-provider", &Spec{
+		Services: []string{"lazy-a"},
+		Creator:  func() Provider { return a },
+	})
+	r.Register("lazy-b-provider", &Spec{
+		Services: []string{"lazy-b"},
+		Creator:  func() Provider { return b },
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"lazy-a-provider": nil,
+		"lazy-b-provider": nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	if b.A != a {
+		t.Errorf("testLazyB.A not injected")
+	}
+	if a.B == nil || a.B() != b {
+		t.Errorf("testLazyA.B() not return lazy-b")
+	}
+	var lazy bool
+	for _, node := range hub.DependencyGraph() {
+		if node.Name != "lazy-a-provider" {
+			continue
+		}
+		if len(node.Deps) > 0 {
+			t.Errorf("dependencies of lazy-a-provider = %v, want empty", node.Deps)
+		}
+		for _, edge := range node.Edges {
+			if edge.To == "lazy-b-provider" && edge.Lazy {
+				lazy = true
+			}
+		}
+	}
+	if !lazy {
+		t.Errorf("lazy edge from lazy-a-provider to lazy-b-provider not found")
+	}
+}
+
+func TestHub_LazyInvalidField(t *testing.T) {
+	type consumer struct {
+		B *testLazyB `autowired:"lazy-b" lazy:"true"`
+	}
+	r := NewRegistry()
+	r.Register("lazy-b-provider", &Spec{
+		Services: []string{"lazy-b"},
+		Creator:  func() Provider { return &testLazyB{} },
+	})
+	r.Register("consumer", &Spec{
+		Creator: func() Provider { return &consumer{} },
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"lazy-b-provider": nil,
+		"consumer":        nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err == nil {
+		t.Fatalf("Hub.Init() = nil, want error of invalid lazy field")
+	}
+}
+
+func TestHub_LazyTypeMismatch(t *testing.T) {
+	type consumer struct {
+		B func() *testLazyA `autowired:"lazy-b" lazy:"true"`
+	}
+	r := NewRegistry()
+	r.Register("lazy-b-provider", &Spec{
+		Services: []string{"lazy-b"},
+		Creator:  func() Provider { return &testStoreImpl{"b"} },
+	})
+	r.Register("consumer", &Spec{
+		Creator: func() Provider { return &consumer{} },
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"lazy-b-provider": nil,
+		"consumer":        nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err == nil || !strings.Contains(err.Error(), "not implement *servicehub.testLazyA of lazy field B") {
+		t.Fatalf("Hub.Init() = %v, want error of lazy field type mismatch", err)
+	}
+
+	// the instances of DependencyProvider are known only on lookup
+	c := &consumer{}
+	r = NewRegistry()
+	r.Register("lazy-b-provider", &Spec{
+		Services: []string{"lazy-b"},
+		Creator:  func() Provider { return &testLifetimeProvider{} },
+	})
+	r.Register("consumer", &Spec{
+		Creator: func() Provider { return c },
+	})
+	hub = New(WithRegistry(r))
+	err = hub.Init(map[string]interface{}{
+		"lazy-b-provider": nil,
+		"consumer":        nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	if b := c.B(); b != nil {
+		t.Errorf("consumer.B() = %v, want nil for mismatched instance", b)
+	}
+}
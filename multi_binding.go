@@ -0,0 +1,65 @@
+package servicehub
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// isMultiBinding return true if the field collects the instances of all providers of a service,
+// the field must be a slice, or a map with string key which is keyed by label of provider,
+// and the instance of provider can not be assigned to the field directly.
+func isMultiBinding(typ reflect.Type, providers []*providerContext) bool {
+	switch typ.Kind() {
+	case reflect.Slice:
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return false
+		}
+	default:
+		return false
+	}
+	for _, pc := range providers {
+		if pc.provider != nil && reflect.TypeOf(pc.provider).AssignableTo(typ) {
+			return false
+		}
+	}
+	return true
+}
+
+// getServices return a slice or map of typ with the instances of all providers of service,
+// providers without label are keyed by their key in map.
+func (h *Hub) getServices(dc DependencyContext, typ reflect.Type) (reflect.Value, error) {
+	providers := h.servicesMap[dc.Service()]
+	elem := typ.Elem()
+	edc := newDependencyContext(dc.Key(), dc.Caller(), dc.CallerLabel(), elem, dc.Tags())
+	edc.hub = h
+	var result reflect.Value
+	if typ.Kind() == reflect.Slice {
+		result = reflect.MakeSlice(typ, 0, len(providers))
+	} else {
+		result = reflect.MakeMapWithSize(typ, len(providers))
+	}
+	for _, pc := range providers {
+		instance := h.decorate(pc, pc.provide(edc), edc)
+		if instance == nil {
+			continue
+		}
+		if !reflect.TypeOf(instance).AssignableTo(elem) {
+			return result, fmt.Errorf("service %q of provider %s not implement %s", dc.Service(), pc.key, elem)
+		}
+		if typ.Kind() == reflect.Slice {
+			result = reflect.Append(result, reflect.ValueOf(instance))
+			continue
+		}
+		key := pc.label
+		if len(key) <= 0 {
+			key = pc.key
+		}
+		kv := reflect.ValueOf(key).Convert(typ.Key())
+		if result.MapIndex(kv).IsValid() {
+			return result, fmt.Errorf("service %q has many providers with key %q", dc.Service(), key)
+		}
+		result.SetMapIndex(kv, reflect.ValueOf(instance))
+	}
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package servicehub
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type testStore interface {
+	Name() string
+}
+
+type testStoreImpl struct{ name string }
+
+func (s *testStoreImpl) Name() string { return s.name }
+
+func TestHub_MultiBinding(t *testing.T) {
+	type consumer struct {
+		List     []testStore          `autowired:"store"`
+		Map      map[string]testStore `autowired:"store"`
+		Labeled  testStore            `autowired:"store@b"`
+		Optional []testStore          `autowired:"not-exist" optional:"true"`
+	}
+	r := NewRegistry()
+	r.Register("memory-store", &Spec{
+		Services: []string{"store"},
+		Creator:  func() Provider { return &testStoreImpl{"memory"} },
+	})
+	r.Register("redis-store", &Spec{
+		Services: []string{"store"},
+		Creator:  func() Provider { return &testStoreImpl{"redis"} },
+	})
+	c := &consumer{}
+	r.Register("consumer", &Spec{
+		Creator: func() Provider { return c },
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"memory-store":  nil,
+		"redis-store@a": nil,
+		"redis-store@b": nil,
+		"consumer":      nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+
+	var list []string
+	for _, s := range c.List {
+		list = append(list, s.Name())
+	}
+	if !reflect.DeepEqual(list, []string{"memory", "redis", "redis"}) {
+		t.Errorf("consumer.List = %v, want all stores", list)
+	}
+	m := make(map[string]string)
+	for k, s := range c.Map {
+		m[k] = s.Name()
+	}
+	if !reflect.DeepEqual(m, map[string]string{"memory-store": "memory", "a": "redis", "b": "redis"}) {
+		t.Errorf("consumer.Map = %v, want stores keyed by label", m)
+	}
+	if c.Labeled == nil || c.Labeled != hub.Provider("redis-store@b") {
+		t.Errorf("consumer.Labeled is not redis-store@b")
+	}
+	if c.Optional != nil {
+		t.Errorf("consumer.Optional = %v, want nil", c.Optional)
+	}
+	for _, node := range hub.DependencyGraph() {
+		if node.Name == "consumer" {
+			deps := append([]string{}, node.Deps...)
+			sort.Strings(deps)
+			if !reflect.DeepEqual(deps, []string{"memory-store", "redis-store"}) {
+				t.Errorf("dependencies of consumer = %v, want all store providers", deps)
+			}
+		}
+	}
+}
+
+func TestHub_MultiBindingAmbiguous(t *testing.T) {
+	type consumer struct {
+		Store testStore `autowired:"store"`
+	}
+	r := NewRegistry()
+	r.Register("memory-store", &Spec{
+		Services: []string{"store"},
+		Creator:  func() Provider { return &testStoreImpl{"memory"} },
+	})
+	r.Register("redis-store", &Spec{
+		Services: []string{"store"},
+		Creator:  func() Provider { return &testStoreImpl{"redis"} },
+	})
+	r.Register("consumer", &Spec{
+		Creator: func() Provider { return &consumer{} },
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"memory-store":  nil,
+		"redis-store@a": nil,
+		"consumer":      nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err == nil || !strings.Contains(err.Error(), "ambiguous providers of service store, candidates: memory-store, redis-store@a") {
+		t.Fatalf("Hub.Init() = %v, want ambiguous error", err)
+	}
+	if instance := hub.Service("store"); instance != nil {
+		t.Errorf("hub.Service(store) = %v, want nil", instance)
+	}
+	if hub.Service("store@a") != hub.Provider("redis-store@a") {
+		t.Errorf("hub.Service(store@a) is not redis-store@a")
+	}
+}
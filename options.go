@@ -1,6 +1,10 @@
 package servicehub
 
-import "github.com/recallsong/servicehub/logs"
+import (
+	"time"
+
+	"github.com/recallsong/servicehub/logs"
+)
 
 // Option .
 type Option func(hub *Hub)
@@ -18,6 +22,60 @@ func WithLogger(logger logs.Logger) interface{} {
 	})
 }
 
+// WithRegistry load providers from the registry instead of the default registry.
+func WithRegistry(r *Registry) interface{} {
+	return Option(func(hub *Hub) {
+		hub.registry = r
+	})
+}
+
+// WithOrderedStart start providers in dependency order, a provider is started only after all its dependencies are ready.
+// readyTimeout limits the time each provider can take to become ready, zero means no limit.
+func WithOrderedStart(readyTimeout time.Duration) interface{} {
+	return Option(func(hub *Hub) {
+		hub.orderedStart = true
+		hub.readyTimeout = readyTimeout
+	})
+}
+
+// WithParallelInit init the providers of the same dependency level concurrently.
+func WithParallelInit() interface{} {
+	return Option(func(hub *Hub) {
+		hub.parallelInit = true
+	})
+}
+
+// WithPanicMode setup what happens when Start, Run, Ready or a task of provider panics, default is PanicCrash.
+func WithPanicMode(mode PanicMode) interface{} {
+	return Option(func(hub *Hub) {
+		hub.panicMode = mode
+	})
+}
+
+// WithHealthCheckTimeout setup the timeout of each provider health check, default is 5s, zero means no limit.
+func WithHealthCheckTimeout(timeout time.Duration) interface{} {
+	return Option(func(hub *Hub) {
+		hub.healthTimeout = timeout
+	})
+}
+
+// WithConfigReload reload config on SIGHUP instead of exiting, and also when the config files changed if interval > 0,
+// see Hub.Reload.
+func WithConfigReload(interval time.Duration) interface{} {
+	return Option(func(hub *Hub) {
+		hub.reloadConfig = true
+		hub.watchInterval = interval
+	})
+}
+
+// WithStrictConfig reject config keys which are not mapped to any field of provider config,
+// it is the same as the flag --config.strict.
+func WithStrictConfig() interface{} {
+	return Option(func(hub *Hub) {
+		hub.strictConfig = true
+	})
+}
+
 // Listener .
 type Listener interface {
 	BeforeInitialization(h *Hub, config map[string]interface{}) error
@@ -0,0 +1,50 @@
+package servicehub
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicMode decides what happens when a provider goroutine panics.
+type PanicMode string
+
+// panic modes
+const (
+	PanicCrash   PanicMode = "crash"   // let the panic crash the process
+	PanicRecover PanicMode = "recover" // recover the panic as a PanicError and shut down the hub gracefully
+)
+
+// PanicError is the error recovered from a panic in Start, Run, Ready or a task of provider.
+type PanicError struct {
+	Provider string
+	Task     string
+	Value    interface{}
+	Stack    []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in provider %s %s: %v", e.Provider, e.Task, e.Value)
+}
+
+// recoverFunc wrap fn to recover panic as PanicError if the hub runs in PanicRecover mode.
+func (h *Hub) recoverFunc(provider, task string, fn func(context.Context) error) func(context.Context) error {
+	if h.panicMode != PanicRecover {
+		return fn
+	}
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				perr := &PanicError{
+					Provider: provider,
+					Task:     task,
+					Value:    r,
+					Stack:    debug.Stack(),
+				}
+				h.logger.Errorf("%s\n%s", perr, perr.Stack)
+				err = perr
+			}
+		}()
+		return fn(ctx)
+	}
+}
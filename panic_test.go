@@ -0,0 +1,69 @@
+package servicehub
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestHub_PanicRecover(t *testing.T) {
+	providers := []testDefine{
+		testRegister("test1", nil, nil, func() Provider {
+			return &testRunFuncProvider{func(ctx context.Context) error {
+				panic("test panic")
+			}}
+		}),
+	}
+	for _, p := range providers {
+		Register(p.name, p.spec)
+	}
+	defer func() {
+		for _, p := range providers {
+			defaultRegistry.Unregister(p.name)
+		}
+	}()
+
+	hub := New(WithPanicMode(PanicRecover))
+	err := hub.Init(map[string]interface{}{
+		testProviderName("test1"): nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	err = hub.Start()
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Hub.Start() = %v, want *PanicError", err)
+	}
+	if perr.Provider != testProviderName("test1") || perr.Task != "Run" || perr.Value != "test panic" || len(perr.Stack) <= 0 {
+		t.Errorf("PanicError = %+v, not match", perr)
+	}
+	if err := hub.Close(); err != nil {
+		t.Errorf("Hub.Close() = %v, want nil", err)
+	}
+}
+
+type testPanicReadyProvider struct{}
+
+func (p *testPanicReadyProvider) Ready(ctx context.Context) error { panic("test panic") }
+
+func TestHub_PanicRecoverReady(t *testing.T) {
+	r := NewRegistry()
+	r.Register("ready-provider", &Spec{Creator: func() Provider { return &testPanicReadyProvider{} }})
+	hub := New(WithRegistry(r), WithPanicMode(PanicRecover), WithOrderedStart(0))
+	err := hub.Init(map[string]interface{}{"ready-provider@a": nil}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	err = hub.Start()
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Hub.Start() = %v, want *PanicError", err)
+	}
+	if perr.Provider != "ready-provider@a (ready-provider)" || perr.Task != "Ready" {
+		t.Errorf("PanicError = %+v, want provider named like Start and Run", perr)
+	}
+	hub.Close()
+}
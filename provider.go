@@ -42,21 +42,26 @@ type ServiceDependencies interface {
 	Dependencies(*Hub) []string
 }
 
+// ProviderConstructor is implemented by defines which create provider by a constructor function,
+// such as func(cfg *Config, db DB, log logs.Logger) (Provider, error).
+// The parameters are injected as dependencies, services are the service names of parameters,
+// an empty service name means the parameter is injected by type.
+type ProviderConstructor interface {
+	Constructor() (fn interface{}, services []string)
+}
+
 // ConfigCreator .
 type ConfigCreator interface {
 	Config() interface{}
 }
 
-// serviceProviders .
-var serviceProviders = map[string]ProviderDefine{}
-
-// RegisterProvider .
+// RegisterProvider register provider to the default registry, exit if the name already exist.
 func RegisterProvider(name string, define ProviderDefine) {
-	if _, ok := serviceProviders[name]; ok {
-		fmt.Printf("provider %s already exist\n", name)
+	err := defaultRegistry.RegisterProvider(name, define)
+	if err != nil {
+		fmt.Println(err)
 		os.Exit(-1)
 	}
-	serviceProviders[name] = define
 }
 
 // Provider .
@@ -89,6 +94,12 @@ type ProviderRunnerWithContext interface {
 	Run(context.Context) error
 }
 
+// ProviderReadiness is implemented by providers that need time to become ready after starting,
+// with ordered start, providers depending on it are not started until Ready returns nil.
+type ProviderReadiness interface {
+	Ready(ctx context.Context) error
+}
+
 // ProviderInitializer .
 type ProviderInitializer interface {
 	Init(ctx Context) error
@@ -105,6 +116,12 @@ type DependencyContext interface {
 	CallerLabel() string
 }
 
+// DependencyHub is implemented by the DependencyContext of Hub, such as the one passed to Decorator,
+// to lookup other services.
+type DependencyHub interface {
+	Hub() *Hub
+}
+
 // DependencyProvider .
 type DependencyProvider interface {
 	Provide(ctx DependencyContext, options ...interface{}) interface{}
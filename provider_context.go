@@ -2,11 +2,14 @@ package servicehub
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/recallsong/go-utils/config"
 	"github.com/recallsong/go-utils/encoding/jsonx"
@@ -23,48 +26,82 @@ type providerContext struct {
 	label       string
 	name        string
 	cfg         interface{}
+	rawCfg      interface{}
 	provider    Provider
 	structValue reflect.Value
 	structType  reflect.Type
 	define      ProviderDefine
 	tasks       []task
+	depends     []string
+	ready       chan struct{}
+	readyErr    error
+	exitTimeout time.Duration
+	restart     restartOptions
+	constructor *constructor
+	runCtx      context.Context
+	runCancel   func()
+	wg          sync.WaitGroup
+
+	lifetime     Lifetime
+	instanceOnce sync.Once
+	instance     interface{}
+	instances    sync.Map // instances of dependents with LifetimePerDependent
+
+	bindings map[string]string // service references bound to other services by _bindings
 }
 
 var loggerType = reflect.TypeOf((*logs.Logger)(nil)).Elem()
 
 func (c *providerContext) BindConfig(flags *pflag.FlagSet) (err error) {
+	cfg, err := c.bindConfig(c.cfg, flags)
+	if err != nil {
+		return err
+	}
+	c.cfg = cfg
+	return nil
+}
+
+// bindConfig create the config of provider and bind default values, raw config, env and flags to it.
+func (c *providerContext) bindConfig(raw interface{}, flags *pflag.FlagSet) (interface{}, error) {
 	if creator, ok := c.define.(ConfigCreator); ok {
 		cfg := creator.Config()
 		if cfg != nil {
-			err = unmarshal.BindDefault(cfg)
+			err := unmarshal.BindDefault(cfg)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			if c.cfg != nil {
-				err = config.ConvertData(c.cfg, cfg, "file")
+			if raw != nil {
+				err = config.ConvertData(raw, cfg, "file")
 				if err != nil {
-					return err
+					return nil, err
 				}
 			}
 			err = unmarshal.BindEnv(cfg)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			if flags != nil {
 				err = unmarshalflag.BindFlag(flags, cfg)
 				if err != nil {
-					return err
+					return nil, err
 				}
 			}
-			c.cfg = cfg
-			return nil
+			return cfg, nil
 		}
 	}
-	c.cfg = nil
-	return nil
+	return nil, nil
 }
 
 func (c *providerContext) Init() (err error) {
+	if c.constructor != nil {
+		c.provider, err = c.constructor.construct(c)
+		if err != nil {
+			return fmt.Errorf("failed to construct provider %s: %s", c.name, err)
+		}
+	}
+	if err = c.checkLifetime(); err != nil {
+		return err
+	}
 	if reflect.ValueOf(c.provider).Kind() == reflect.Ptr && c.structType != nil {
 		value, typ := c.structValue, c.structType
 		var (
@@ -86,6 +123,18 @@ func (c *providerContext) Init() (err error) {
 				logger := c.Logger()
 				value.Field(i).Set(reflect.ValueOf(logger))
 			}
+			if val, ok, err := c.builtinField(field); err != nil {
+				return fmt.Errorf("invalid field %s.%s: %s", typ.String(), field.Name, err)
+			} else if ok {
+				value.Field(i).Set(val)
+				continue
+			}
+			if path, ok := field.Tag.Lookup("config"); ok {
+				if err := c.injectConfig(value.Field(i), path); err != nil {
+					return fmt.Errorf("failed to inject config into %s.%s: %s", typ.String(), field.Name, err)
+				}
+				continue
+			}
 			if cfgValue != nil && field.Type == cfgType {
 				value.Field(i).Set(*cfgValue)
 			}
@@ -98,14 +147,38 @@ func (c *providerContext) Init() (err error) {
 				continue
 			}
 
-			dc := newDependencyContext(
+			ltyp, lazy, err := lazyType(field)
+			if err != nil {
+				return fmt.Errorf("invalid field %s.%s: %s", typ.String(), field.Name, err)
+			}
+			if lazy {
+				dc := newDependencyContext(service, c.name, c.label, ltyp, field.Tag)
+				value.Field(i).Set(c.hub.lazyService(dc, field.Type))
+				continue
+			}
+
+			dc := c.hub.bindDependency(newDependencyContext(
 				service,
 				c.name,
 				c.label,
 				field.Type,
 				field.Tag,
-			)
-			instance := c.hub.getService(dc)
+			))
+			if len(service) > 0 && len(dc.Label()) <= 0 {
+				providers := c.hub.servicesMap[dc.Service()]
+				if len(providers) > 0 && isMultiBinding(field.Type, providers) {
+					val, err := c.hub.getServices(dc, field.Type)
+					if err != nil {
+						return fmt.Errorf("failed to inject %s.%s: %s", typ.String(), field.Name, err)
+					}
+					value.Field(i).Set(val)
+					continue
+				}
+			}
+			instance, err := c.hub.getService(dc)
+			if err != nil {
+				return fmt.Errorf("failed to inject %s.%s: %s", typ.String(), field.Name, err)
+			}
 			if len(service) > 0 && instance == nil {
 				opt, err := boolTagValue(field.Tag, "optional", false)
 				if err != nil {
@@ -145,6 +218,40 @@ func (c *providerContext) Init() (err error) {
 	return nil
 }
 
+// waitReady wait the provider to be ready, key is the provider in logs and errors like Start and Run.
+func (c *providerContext) waitReady(ctx context.Context, key string, timeout time.Duration) error {
+	r, ok := c.provider.(ProviderReadiness)
+	if !ok {
+		return nil
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	ch := make(chan error, 1)
+	go func() {
+		ch <- c.hub.recoverFunc(key, "Ready", r.Ready)(ctx)
+	}()
+	select {
+	case err := <-ch:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("not ready within %s", timeout)
+		}
+		return ctx.Err()
+	}
+}
+
+// provide return the instance of provider for the dependent.
+func (c *providerContext) provide(dc DependencyContext, options ...interface{}) interface{} {
+	if prod, ok := c.provider.(DependencyProvider); ok {
+		return c.provideWithLifetime(prod, dc, options...)
+	}
+	return c.provider
+}
+
 // Define .
 func (c *providerContext) Define() ProviderDefine {
 	return c.define
@@ -178,21 +285,65 @@ func boolTagValue(tag reflect.StructTag, key string, defval bool) (bool, error)
 
 // Dependencies .
 func (c *providerContext) Dependencies() (services []string, providers []string) {
-	srvset, provset := make(map[string]bool), make(map[reflect.Type]bool)
+	srvset, provset := make(map[string]bool), make(map[string]bool)
+	list, _ := c.dependencyList()
+	for _, dep := range list {
+		if len(dep.provider) > 0 {
+			if !provset[dep.provider] {
+				providers = append(providers, dep.provider)
+				provset[dep.provider] = true
+			}
+		} else if !srvset[dep.service] {
+			services = append(services, dep.service)
+			srvset[dep.service] = true
+		}
+	}
+	return
+}
 
+// sources of dependency
+const (
+	dependencySourceDefine    = "dependencies"   // declared by ServiceDependencies, such as Spec.Dependencies
+	dependencySourceTag       = "service-tag"    // field with service or autowired tag
+	dependencySourceType      = "autowired-type" // field autowired by type
+	dependencySourceCtor      = "constructor"    // parameter of constructor
+	dependencySourceDecorator = "decorator"      // declared by decorator of the services of provider
+)
+
+// dependency describes a dependency of provider and where it comes from.
+type dependency struct {
+	service  string // service name, maybe with @label
+	provider string // provider name, only for dependency by type
+	source   string
+	field    string
+	tags     reflect.StructTag
+	lazy     bool         // resolved on first use, not affect the init order
+	typ      reflect.Type // type of lazy service
+	multi    bool         // injected with all providers of service, see isMultiBinding
+}
+
+func (c *providerContext) dependencyList() (list []*dependency, err error) {
 	if deps, ok := c.define.(ServiceDependencies); ok {
 		for _, service := range deps.Dependencies(c.hub) {
-			if !srvset[service] {
-				services = append(services, service)
-				srvset[service] = true
-			}
+			list = append(list, &dependency{service: service, source: dependencySourceDefine})
 		}
 	}
 
+	if c.constructor != nil {
+		deps, err := c.constructor.dependencies(c)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, deps...)
+	}
+
 	if c.structType != nil {
 		fields := c.structType.NumField()
 		for i := 0; i < fields; i++ {
 			field := c.structType.Field(i)
+			if _, ok := field.Tag.Lookup("config"); ok || isBuiltinField(field) {
+				continue
+			}
 			service := field.Tag.Get("service")
 			if len(service) <= 0 {
 				service = field.Tag.Get("autowired")
@@ -200,30 +351,88 @@ func (c *providerContext) Dependencies() (services []string, providers []string)
 			if service == "-" {
 				continue
 			}
+			ltyp, lazy, _ := lazyType(field)
 			if len(service) > 0 {
 				opt, _ := boolTagValue(field.Tag, "optional", false)
-				if opt {
-					if len(c.hub.servicesMap[service]) > 0 && !srvset[service] {
-						services = append(services, service)
-						srvset[service] = true
-					}
-				} else if !srvset[service] {
-					services = append(services, service)
-					srvset[service] = true
+				if opt && len(c.hub.servicesMap[service]) <= 0 {
+					continue
 				}
+				list = append(list, &dependency{
+					service: service,
+					source:  dependencySourceTag,
+					field:   field.Name,
+					tags:    field.Tag,
+					lazy:    lazy,
+					typ:     ltyp,
+					multi:   !lazy && isMultiBinding(field.Type, c.hub.servicesMap[service]),
+				})
 				continue
 			}
 			if !c.structValue.Field(i).CanSet() {
 				continue
 			}
-			plist := c.hub.servicesTypes[field.Type]
-			if len(plist) > 0 && !provset[field.Type] {
-				provset[field.Type] = true
-				providers = append(providers, plist[0].name)
+			typ := field.Type
+			if lazy {
+				typ = ltyp
+			}
+			plist, err := c.hub.findTypeProviders(typ, isAutowired(field.Tag))
+			if err != nil {
+				return nil, fmt.Errorf("failed to autowire %s.%s: %s", c.structType.String(), field.Name, err)
+			}
+			if len(plist) > 0 {
+				list = append(list, &dependency{provider: plist[0].name, source: dependencySourceType, field: field.Name, lazy: lazy})
 			}
 		}
 	}
-	return
+	list = append(list, c.decoratorDependencies()...)
+	return list, nil
+}
+
+// autowiredTag is the tag of dependencies looked up by type which are declared explicitly,
+// such as the parameters of constructor.
+const autowiredTag = reflect.StructTag(`autowired:""`)
+
+// isAutowired return true if the field is tagged with autowired, its type can be resolved to an assignable type.
+func isAutowired(tags reflect.StructTag) bool {
+	_, ok := tags.Lookup("autowired")
+	return ok
+}
+
+// isBuiltinField return true if the field is injected with Context, Hub or meta of provider.
+func isBuiltinField(field reflect.StructField) bool {
+	if _, ok := field.Tag.Lookup("meta"); ok {
+		return true
+	}
+	return field.Type == contextType || field.Type == hubType
+}
+
+// builtinField return the value of field which is injected with Context, Hub or meta of provider,
+// meta can be key, name or label of provider.
+func (c *providerContext) builtinField(field reflect.StructField) (reflect.Value, bool, error) {
+	if meta, ok := field.Tag.Lookup("meta"); ok {
+		if field.Type.Kind() != reflect.String {
+			return reflect.Value{}, false, fmt.Errorf("meta field must be string, but got %s", field.Type)
+		}
+		var val string
+		switch meta {
+		case "key":
+			val = c.key
+		case "name":
+			val = c.name
+		case "label":
+			val = c.label
+		default:
+			return reflect.Value{}, false, fmt.Errorf("unknown meta %q", meta)
+		}
+		return reflect.ValueOf(val).Convert(field.Type), true, nil
+	}
+	switch field.Type {
+	case contextType:
+		return reflect.ValueOf(c), true, nil
+	case hubType:
+		return reflect.ValueOf(c.hub), true, nil
+	}
+	return reflect.Value{}, false, nil
 }
 
 // Hub .
@@ -246,20 +455,25 @@ func (c *providerContext) Config() interface{} {
 
 // Service .
 func (c *providerContext) Service(name string, options ...interface{}) interface{} {
-	return c.hub.getService(newDependencyContext(
+	instance, err := c.hub.getService(newDependencyContext(
 		name,
 		c.name,
 		c.label,
 		nil,
 		reflect.StructTag(""),
 	), options...)
+	if err != nil {
+		c.hub.logger.Errorf("provider %s failed to get service %s: %s", c.key, name, err)
+	}
+	return instance
 }
 
 // AddTask .
 func (c *providerContext) AddTask(fn func(context.Context) error, options ...TaskOption) {
 	t := task{
-		name: "",
-		fn:   fn,
+		name:    "",
+		fn:      fn,
+		restart: c.restart,
 	}
 	for _, opt := range options {
 		opt(&t)
@@ -290,8 +504,9 @@ func WithTaskName(name string) TaskOption {
 }
 
 type task struct {
-	name string
-	fn   func(context.Context) error
+	name    string
+	fn      func(context.Context) error
+	restart restartOptions
 }
 
 // dependencyContext .
@@ -303,6 +518,9 @@ type dependencyContext struct {
 	label       string
 	caller      string
 	callerLabel string
+	scope       *Scope
+	hub         *Hub
+	bound       bool // binding has been applied
 }
 
 func (dc *dependencyContext) Type() reflect.Type      { return dc.typ }
@@ -312,6 +530,7 @@ func (dc *dependencyContext) Key() string             { return dc.key }
 func (dc *dependencyContext) Label() string           { return dc.label }
 func (dc *dependencyContext) Caller() string          { return dc.caller }
 func (dc *dependencyContext) CallerLabel() string     { return dc.callerLabel }
+func (dc *dependencyContext) Hub() *Hub               { return dc.hub }
 
 func newDependencyContext(service, caller, callerLabel string, typ reflect.Type, tags reflect.StructTag) *dependencyContext {
 	dc := &dependencyContext{
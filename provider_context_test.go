@@ -99,3 +99,66 @@ func Test_providerContext_BindConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_providerContext_InjectBuiltin(t *testing.T) {
+	type provider struct {
+		Ctx   Context
+		Hub   *Hub
+		Key   string `meta:"key"`
+		Name  string `meta:"name"`
+		Label string `meta:"label"`
+	}
+	tests := []struct {
+		name    string
+		creator func() Provider
+		want    func(h *Hub, p Provider) bool
+		wantErr bool
+	}{
+		{
+			name:    "inject",
+			creator: func() Provider { return &provider{} },
+			want: func(h *Hub, p Provider) bool {
+				v := p.(*provider)
+				return v.Ctx != nil && v.Ctx.Key() == "test-provider@a" && v.Hub == h &&
+					v.Key == "test-provider@a" && v.Name == "test-provider" && v.Label == "a"
+			},
+		},
+		{
+			name: "unknown meta",
+			creator: func() Provider {
+				return &struct {
+					Value string `meta:"unknown"`
+				}{}
+			},
+			wantErr: true,
+		},
+		{
+			name: "not string meta",
+			creator: func() Provider {
+				return &struct {
+					Value int `meta:"key"`
+				}{}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register("test-provider", &Spec{Creator: tt.creator})
+			hub := New(WithRegistry(r))
+			err := hub.Init(map[string]interface{}{
+				"test-provider@a": nil,
+			}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Hub.Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !tt.want(hub, hub.Provider("test-provider@a")) {
+				t.Errorf("builtin fields not injected: %+v", hub.Provider("test-provider@a"))
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package admin
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/recallsong/servicehub"
+	"github.com/recallsong/servicehub/logs"
+)
+
+type config struct {
+	Addr string `file:"addr" flag:"admin.addr" desc:"admin http server address, such as 127.0.0.1:7098, the server is disabled if empty"`
+}
+
+type provider struct {
+	Cfg    *config
+	Log    logs.Logger
+	hub    *servicehub.Hub
+	server *http.Server
+}
+
+func (p *provider) Init(ctx servicehub.Context) error {
+	p.hub = ctx.Hub()
+	p.server = &http.Server{Handler: p.handler()}
+	return nil
+}
+
+func (p *provider) Run(ctx context.Context) error {
+	if len(p.Cfg.Addr) <= 0 {
+		p.Log.Debugf("admin server is disabled, set admin.addr to enable it")
+		return nil
+	}
+	lis, err := net.Listen("tcp", p.Cfg.Addr)
+	if err != nil {
+		return err
+	}
+	p.Log.Infof("admin server listening at %s", lis.Addr())
+	go func() {
+		<-ctx.Done()
+		p.server.Close()
+	}()
+	err = p.server.Serve(lis)
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+func (p *provider) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/providers", func(rw http.ResponseWriter, r *http.Request) {
+		type item struct {
+			Key          string   `json:"key"`
+			Name         string   `json:"name"`
+			Label        string   `json:"label,omitempty"`
+			Services     []string `json:"services,omitempty"`
+			Dependencies []string `json:"dependencies,omitempty"`
+		}
+		var list []*item
+		for _, info := range p.hub.ProviderInfos() {
+			list = append(list, &item{
+				Key:          info.Key,
+				Name:         info.Name,
+				Label:        info.Label,
+				Services:     info.Services,
+				Dependencies: info.Dependencies,
+			})
+		}
+		writeJSON(rw, http.StatusOK, list)
+	})
+	mux.HandleFunc("/graph", func(rw http.ResponseWriter, r *http.Request) {
+		writeJSON(rw, http.StatusOK, p.hub.DependencyGraph())
+	})
+	mux.HandleFunc("/tasks", func(rw http.ResponseWriter, r *http.Request) {
+		writeJSON(rw, http.StatusOK, p.hub.RunningTasks())
+	})
+	mux.HandleFunc("/config", func(rw http.ResponseWriter, r *http.Request) {
+		configs := make(map[string]interface{})
+		for _, info := range p.hub.ProviderInfos() {
+			configs[info.Key] = redact(reflect.ValueOf(info.Config))
+		}
+		writeJSON(rw, http.StatusOK, configs)
+	})
+	mux.HandleFunc("/config/sources", func(rw http.ResponseWriter, r *http.Request) {
+		writeJSON(rw, http.StatusOK, p.hub.ConfigSources())
+	})
+	health := func(kind servicehub.HealthCheckKind) http.HandlerFunc {
+		return func(rw http.ResponseWriter, r *http.Request) {
+			report := p.hub.Health(r.Context(), kind)
+			status := http.StatusOK
+			if report.Status != servicehub.HealthUp {
+				status = http.StatusServiceUnavailable
+			}
+			writeJSON(rw, status, report)
+		}
+	}
+	mux.HandleFunc("/health", health(servicehub.Readiness))
+	mux.HandleFunc("/health/liveness", health(servicehub.Liveness))
+	mux.HandleFunc("/health/readiness", health(servicehub.Readiness))
+	return mux
+}
+
+// redactedValue replaces the values of fields tagged with secret:"true" or sensitive:"true"
+const redactedValue = "******"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// redact convert the config to the value encoded as json, in which the non-empty secret fields are redacted.
+func redact(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redact(v.Elem())
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		redactFields(v, m)
+		return m
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = redact(iter.Value())
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = redact(v.Index(i))
+		}
+		return list
+	}
+	return v.Interface()
+}
+
+// redactFields put the exported fields of struct into m, keyed by the names encoded as json.
+func redactFields(v reflect.Value, m map[string]interface{}) {
+	typ := v.Type()
+	for i, num := 0, typ.NumField(); i < num; i++ {
+		field := typ.Field(i)
+		if len(field.PkgPath) > 0 && !field.Anonymous {
+			continue // unexported
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if field.Anonymous && len(name) <= 0 {
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				redactFields(fv, m) // embedded fields are promoted
+				continue
+			}
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		if len(name) <= 0 {
+			name = field.Name
+		}
+		if isSecret(field) && !fv.IsZero() {
+			m[name] = redactedValue
+			continue
+		}
+		m[name] = redact(fv)
+	}
+}
+
+func isSecret(field reflect.StructField) bool {
+	for _, key := range []string{"secret", "sensitive"} {
+		if ok, _ := strconv.ParseBool(field.Tag.Get(key)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(rw http.ResponseWriter, status int, data interface{}) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	rw.WriteHeader(status)
+	enc := json.NewEncoder(rw)
+	enc.SetIndent("", "  ")
+	enc.Encode(data)
+}
+
+func init() {
+	servicehub.RegisterGlobalSpec("servicehub-admin", &servicehub.Spec{
+		Services:    []string{"servicehub-admin"},
+		Description: "admin http server to inspect providers, dependency graph, running tasks, config and health of service hub",
+		ConfigFunc:  func() interface{} { return &config{} },
+		Creator: func() servicehub.Provider {
+			return &provider{}
+		},
+	})
+}
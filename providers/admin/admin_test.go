@@ -0,0 +1,149 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/recallsong/servicehub"
+	"github.com/recallsong/servicehub/logs/logrusx"
+	"github.com/spf13/pflag"
+)
+
+func Test_provider_handler(t *testing.T) {
+	servicehub.Register("admin-test-consumer", &servicehub.Spec{
+		Dependencies: []string{"servicehub-admin"},
+		Creator:      func() servicehub.Provider { return "consumer" },
+	})
+	defer servicehub.DefaultRegistry().Unregister("admin-test-consumer")
+	hub := servicehub.New()
+	err := hub.Init(map[string]interface{}{"admin-test-consumer": nil}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	p, ok := hub.Provider("servicehub-admin").(*provider)
+	if !ok {
+		t.Fatalf("servicehub-admin provider not loaded")
+	}
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/providers", http.StatusOK},
+		{"/graph", http.StatusOK},
+		{"/tasks", http.StatusOK},
+		{"/config", http.StatusOK},
+		{"/config/sources", http.StatusOK},
+		{"/health/liveness", http.StatusOK},
+		{"/health/readiness", http.StatusServiceUnavailable}, // hub is not started
+	}
+	handler := p.handler()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rw.Code != tt.status {
+				t.Errorf("GET %s status = %d, want %d", tt.path, rw.Code, tt.status)
+			}
+			var body interface{}
+			if err := json.Unmarshal(rw.Body.Bytes(), &body); err != nil {
+				t.Errorf("GET %s returns invalid json: %s", tt.path, err)
+			}
+		})
+	}
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/config", nil))
+	var configs map[string]*config
+	if err := json.Unmarshal(rw.Body.Bytes(), &configs); err != nil {
+		t.Fatalf("invalid config response: %s", err)
+	}
+	if cfg := configs["servicehub-admin"]; cfg == nil || cfg.Addr != "" {
+		t.Errorf("config of servicehub-admin = %v, want disabled by default", cfg)
+	}
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/providers", nil))
+	var providers []map[string]interface{}
+	if err := json.Unmarshal(rw.Body.Bytes(), &providers); err != nil {
+		t.Fatalf("invalid providers response: %s", err)
+	}
+	var deps interface{}
+	for _, item := range providers {
+		if item["key"] == "admin-test-consumer" {
+			deps = item["dependencies"]
+		}
+	}
+	if !reflect.DeepEqual(deps, []interface{}{"servicehub-admin"}) {
+		t.Errorf("dependencies of admin-test-consumer = %v, want [servicehub-admin]", deps)
+	}
+}
+
+func Test_provider_Run(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+	}{
+		{name: "disabled"},
+		{name: "random port", addr: "127.0.0.1:0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &provider{Cfg: &config{Addr: tt.addr}, Log: logrusx.New()}
+			p.server = &http.Server{Handler: p.handler()}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if err := p.Run(ctx); err != nil {
+				t.Errorf("provider.Run() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func Test_redact(t *testing.T) {
+	type tlsConfig struct {
+		Cert string `file:"cert"`
+		Key  string `file:"key" secret:"true"`
+	}
+	type Base struct {
+		Token string `sensitive:"true"`
+	}
+	type cfg struct {
+		Base
+		Addr     string            `json:"addr"`
+		Password string            `json:"password" secret:"true"`
+		Empty    string            `json:"empty" secret:"true"`
+		Timeout  time.Duration     `json:"timeout"`
+		TLS      *tlsConfig        `json:"tls"`
+		Backends []tlsConfig       `json:"backends"`
+		Labels   map[string]string `json:"labels"`
+		Ignored  string            `json:"-"`
+		internal string
+	}
+	got := redact(reflect.ValueOf(&cfg{
+		Base:     Base{Token: "token"},
+		Addr:     ":8080",
+		Password: "password",
+		Timeout:  time.Second,
+		TLS:      &tlsConfig{Cert: "cert.pem", Key: "key"},
+		Backends: []tlsConfig{{Cert: "a.pem", Key: "a"}},
+		Ignored:  "ignored",
+		internal: "internal",
+	}))
+	want := map[string]interface{}{
+		"Token":    redactedValue,
+		"addr":     ":8080",
+		"password": redactedValue,
+		"empty":    "",
+		"timeout":  time.Second,
+		"tls":      map[string]interface{}{"Cert": "cert.pem", "Key": redactedValue},
+		"backends": []interface{}{map[string]interface{}{"Cert": "a.pem", "Key": redactedValue}},
+		"labels":   nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("redact() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package servicehub
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ConflictPolicy decides what happens when a provider is registered with an existing name.
+type ConflictPolicy string
+
+// conflict policies
+const (
+	ConflictError   ConflictPolicy = "error"   // return an error and keep the existing provider
+	ConflictReplace ConflictPolicy = "replace" // replace the existing provider
+)
+
+// Registry holds the providers which can be loaded by Hub.
+// The package level functions, such as Register and RegisterProvider, use the default registry.
+type Registry struct {
+	lock       sync.RWMutex
+	providers  map[string]ProviderDefine
+	globals    map[string]ProviderDefine
+	decorators []*decorator
+	policy     ConflictPolicy
+}
+
+// NewRegistry create a Registry, the default conflict policy is ConflictError.
+func NewRegistry(policy ...ConflictPolicy) *Registry {
+	r := &Registry{
+		providers: make(map[string]ProviderDefine),
+		globals:   make(map[string]ProviderDefine),
+		policy:    ConflictError,
+	}
+	if len(policy) > 0 {
+		r.policy = policy[0]
+	}
+	return r
+}
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry return the registry used by the package level register functions.
+func DefaultRegistry() *Registry { return defaultRegistry }
+
+// Register register a provider defined by Spec.
+func (r *Registry) Register(name string, spec *Spec) error {
+	return r.RegisterProvider(name, &specDefine{spec})
+}
+
+// RegisterProvider .
+func (r *Registry) RegisterProvider(name string, define ProviderDefine) error {
+	return r.add(r.providers, "provider", name, define)
+}
+
+// RegisterGlobalSpec register a global provider defined by Spec.
+func (r *Registry) RegisterGlobalSpec(name string, spec *Spec) error {
+	return r.RegisterGlobalProvider(name, &specDefine{spec})
+}
+
+// RegisterGlobalProvider register a provider which is loaded by Hub without config.
+func (r *Registry) RegisterGlobalProvider(name string, define ProviderDefine) error {
+	return r.add(r.globals, "global provider", name, define)
+}
+
+func (r *Registry) add(m map[string]ProviderDefine, kind, name string, define ProviderDefine) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := m[name]; ok && r.policy != ConflictReplace {
+		return fmt.Errorf("%s %s already exist", kind, name)
+	}
+	m[name] = define
+	return nil
+}
+
+// Unregister remove the provider or global provider, return false if not exist.
+func (r *Registry) Unregister(name string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	_, ok1 := r.providers[name]
+	_, ok2 := r.globals[name]
+	delete(r.providers, name)
+	delete(r.globals, name)
+	return ok1 || ok2
+}
+
+// Get return the provider or global provider.
+func (r *Registry) Get(name string) (ProviderDefine, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	define, ok := r.providers[name]
+	if !ok {
+		define, ok = r.globals[name]
+	}
+	return define, ok
+}
+
+// Names return the names of providers, not including global providers.
+func (r *Registry) Names() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return sortedNames(r.providers)
+}
+
+// GlobalNames return the names of global providers.
+func (r *Registry) GlobalNames() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return sortedNames(r.globals)
+}
+
+func sortedNames(m map[string]ProviderDefine) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,100 @@
+package servicehub
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  []ConflictPolicy
+		wantErr bool
+	}{
+		{
+			name:    "default",
+			wantErr: true,
+		},
+		{
+			name:    "error",
+			policy:  []ConflictPolicy{ConflictError},
+			wantErr: true,
+		},
+		{
+			name:   "replace",
+			policy: []ConflictPolicy{ConflictReplace},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(tt.policy...)
+			spec1 := &Spec{Creator: func() Provider { return 1 }}
+			spec2 := &Spec{Creator: func() Provider { return 2 }}
+			if err := r.Register("test", spec1); err != nil {
+				t.Fatalf("Registry.Register() = %v, want nil", err)
+			}
+			err := r.Register("test", spec2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Registry.Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			define, ok := r.Get("test")
+			if !ok {
+				t.Fatalf("Registry.Get() not found")
+			}
+			want := spec2
+			if tt.wantErr {
+				want = spec1
+			}
+			if define.(*specDefine).s != want {
+				t.Errorf("Registry.Get() got unexpected provider")
+			}
+			if !r.Unregister("test") {
+				t.Errorf("Registry.Unregister() = false, want true")
+			}
+			if _, ok := r.Get("test"); ok {
+				t.Errorf("Registry.Get() found after Unregister")
+			}
+			if r.Unregister("test") {
+				t.Errorf("Registry.Unregister() = true, want false")
+			}
+		})
+	}
+}
+
+func TestHub_WithRegistry(t *testing.T) {
+	newHub := func(service string) (*Hub, error) {
+		r := NewRegistry()
+		r.Register("test-provider", &Spec{
+			Services: []string{service},
+			Creator:  func() Provider { return service },
+		})
+		r.RegisterGlobalSpec("test-global-provider", &Spec{
+			Creator: func() Provider { return "global" },
+		})
+		hub := New(WithRegistry(r))
+		return hub, hub.Init(map[string]interface{}{
+			"test-provider": nil,
+		}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	}
+	hub1, err := newHub("service1")
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	hub2, err := newHub("service2")
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	if hub1.Service("service1") != "service1" || hub1.Service("service2") != nil {
+		t.Errorf("hub1 got services from other registry")
+	}
+	if hub2.Service("service2") != "service2" || hub2.Service("service1") != nil {
+		t.Errorf("hub2 got services from other registry")
+	}
+	if hub1.Provider("test-global-provider") != "global" {
+		t.Errorf("global provider of registry not loaded")
+	}
+	if _, ok := defaultRegistry.Get("test-provider"); ok {
+		t.Errorf("provider registered to default registry")
+	}
+}
@@ -0,0 +1,321 @@
+package servicehub
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// ConfigReloader is implemented by providers which can apply new config without restart,
+// Reload returns an error to refuse the config.
+type ConfigReloader interface {
+	Reload(cfg interface{}) error
+}
+
+// configLoader loads the config of RunOptions again to reload.
+type configLoader struct {
+	content map[string]interface{}
+	files   []string
+	profile string
+}
+
+func (l *configLoader) load(h *Hub) (map[string]interface{}, map[string]string, error) {
+	cfg := make(map[string]interface{})
+	sources := make(map[string]string)
+	mergeConfig(cfg, l.content, "content", sources)
+	cfg, err := h.loadConfigFiles(l.files, l.profile, cfg, sources)
+	return cfg, sources, err
+}
+
+// stat return the modification of config files, which changes if any file is changed.
+func (l *configLoader) stat() string {
+	var sb strings.Builder
+	for _, file := range l.files {
+		files := []string{file}
+		if len(l.profile) > 0 {
+			files = append(files, profileFile(file, l.profile))
+		}
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil {
+				fmt.Fprintf(&sb, "%s:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+			}
+		}
+	}
+	return sb.String()
+}
+
+// watchConfig reload config on SIGHUP, or config files changed if watchInterval > 0.
+func (h *Hub) watchConfig(done <-chan struct{}) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	defer signal.Stop(sig)
+	var tick <-chan time.Time
+	var last string
+	if h.watchInterval > 0 && h.configLoader != nil {
+		ticker := time.NewTicker(h.watchInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+		last = h.configLoader.stat()
+	}
+	for {
+		select {
+		case <-done:
+			return
+		case <-sig:
+			h.logger.Infof("reload config on signal SIGHUP")
+		case <-tick:
+			stat := h.configLoader.stat()
+			if stat == last {
+				continue
+			}
+			last = stat
+			h.logger.Infof("reload config on files changed")
+		}
+		if err := h.reloadFromLoader(); err != nil {
+			h.logger.Errorf("failed to reload config: %s", err)
+		}
+	}
+}
+
+func (h *Hub) reloadFromLoader() error {
+	if h.configLoader == nil {
+		return fmt.Errorf("no config to reload")
+	}
+	cfg, sources, err := h.configLoader.load(h)
+	if err != nil {
+		return err
+	}
+	err = h.Reload(cfg)
+	if err != nil {
+		return err
+	}
+	h.lock.Lock()
+	h.configSources = sources
+	h.lock.Unlock()
+	return nil
+}
+
+type configChange struct {
+	pc     *providerContext
+	raw    interface{}
+	cfg    interface{}
+	reload bool // cfg changed and Reload of provider must be called
+}
+
+// Reload apply the config to the loaded providers, the providers whose config changed must implement ConfigReloader.
+// Reload is rejected and the previous config is restored if any provider refuses the new config.
+// Only config of providers is reloaded, adding or removing providers, meta keys such as _enable,
+// top-level keys such as bindings, and the sections injected by config tag need restart,
+// Reload is rejected if they changed.
+func (h *Hub) Reload(config map[string]interface{}) error {
+	h.reloadLock.Lock()
+	defer h.reloadLock.Unlock()
+	config, err := interpolateConfig(config)
+	if err != nil {
+		return err
+	}
+	if err := h.checkProviderSections(config); err != nil {
+		return err
+	}
+	var changes []*configChange
+	for _, pc := range h.providers {
+		raw, ok := providerConfig(config, pc)
+		if !ok {
+			continue
+		}
+		if err := h.checkReloadable(pc, raw, config); err != nil {
+			return err
+		}
+		cfg, err := h.reloadProviderConfig(pc, raw)
+		if err != nil {
+			return fmt.Errorf("invalid config of provider %s: %s", pc.name, err)
+		}
+		change := &configChange{pc: pc, raw: raw, cfg: cfg, reload: !reflect.DeepEqual(cfg, pc.cfg)}
+		if !change.reload && reflect.DeepEqual(raw, pc.rawCfg) {
+			continue
+		}
+		if _, ok := pc.provider.(ConfigReloader); change.reload && !ok {
+			return fmt.Errorf("config of provider %s changed, but it not support reload", pc.name)
+		}
+		changes = append(changes, change)
+	}
+	for i, change := range changes {
+		if !change.reload {
+			continue
+		}
+		err := change.pc.provider.(ConfigReloader).Reload(change.cfg)
+		if err == nil {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			prev := changes[j].pc
+			if !changes[j].reload {
+				continue
+			}
+			if rerr := prev.provider.(ConfigReloader).Reload(prev.cfg); rerr != nil {
+				h.logger.Errorf("failed to restore config of provider %s: %s", prev.name, rerr)
+			}
+		}
+		return fmt.Errorf("provider %s refused to reload config: %s", change.pc.name, err)
+	}
+	h.lock.Lock()
+	for _, change := range changes {
+		change.pc.cfg, change.pc.rawCfg = change.cfg, change.raw
+	}
+	h.config = config
+	h.lock.Unlock()
+	for _, change := range changes {
+		if change.reload {
+			h.logger.Infof("provider %s config reloaded", change.pc.name)
+		}
+	}
+	return nil
+}
+
+// checkProviderSections reject the config which adds or removes providers, changes the disabled providers,
+// or changes the top-level keys which are not config of providers, such as bindings and shared sections.
+func (h *Hub) checkProviderSections(config map[string]interface{}) error {
+	prev, next := providerSections(h.config), providerSections(config)
+	for key := range next {
+		if _, ok := prev[key]; !ok {
+			return fmt.Errorf("provider %s is added, it can not be reloaded", key)
+		}
+	}
+	loaded := make(map[string]bool)
+	for _, pc := range h.providers {
+		key := pc.key
+		if len(key) <= 0 {
+			key = pc.name
+		}
+		loaded[key] = true
+	}
+	for key, raw := range prev {
+		next, ok := next[key]
+		if !ok {
+			return fmt.Errorf("provider %s is removed, it can not be reloaded", key)
+		}
+		if !loaded[key] && !reflect.DeepEqual(raw, next) {
+			return fmt.Errorf("config of disabled provider %s changed, it can not be reloaded", key)
+		}
+	}
+	keys := make(map[string]bool)
+	for key := range h.config {
+		keys[key] = true
+	}
+	for key := range config {
+		keys[key] = true
+	}
+	for key := range keys {
+		if key != "bindings" && !strings.HasPrefix(key, "_") {
+			continue
+		}
+		if !reflect.DeepEqual(h.config[key], config[key]) {
+			return fmt.Errorf("config %s changed, it can not be reloaded", key)
+		}
+	}
+	return nil
+}
+
+// providerSections return the config sections of providers keyed by provider key, or name if it is in providers list.
+func providerSections(config map[string]interface{}) map[string]interface{} {
+	sections := make(map[string]interface{})
+	for key, cfg := range config {
+		if key != "providers" && key != "bindings" && !strings.HasPrefix(key, "_") {
+			sections[key] = cfg
+		}
+	}
+	switch providers := config["providers"].(type) {
+	case map[string]interface{}:
+		for key, cfg := range providers {
+			sections[key] = cfg
+		}
+	case []interface{}:
+		for _, item := range providers {
+			if cfg, ok := item.(map[string]interface{}); ok {
+				sections[fmt.Sprint(cfg["_name"])] = cfg
+			}
+		}
+	}
+	return sections
+}
+
+// checkReloadable reject the changes of meta keys and the sections injected by config tag of provider,
+// which are applied only on init.
+func (h *Hub) checkReloadable(pc *providerContext, raw interface{}, config map[string]interface{}) error {
+	old, _ := pc.rawCfg.(map[string]interface{})
+	cur, _ := raw.(map[string]interface{})
+	keys := make(map[string]bool)
+	for key := range old {
+		keys[key] = true
+	}
+	for key := range cur {
+		keys[key] = true
+	}
+	for key := range keys {
+		if strings.HasPrefix(key, "_") && !reflect.DeepEqual(old[key], cur[key]) {
+			return fmt.Errorf("meta config %s of provider %s changed, it can not be reloaded", key, pc.name)
+		}
+	}
+	if pc.structType == nil {
+		return nil
+	}
+	for i, num := 0, pc.structType.NumField(); i < num; i++ {
+		path, ok := pc.structType.Field(i).Tag.Lookup("config")
+		if !ok {
+			continue
+		}
+		prev, _ := lookupConfigSection(pc.rawCfg, h.config, path)
+		next, _ := lookupConfigSection(raw, config, path)
+		if !reflect.DeepEqual(prev, next) {
+			return fmt.Errorf("config %s injected into provider %s changed, it can not be reloaded", path, pc.name)
+		}
+	}
+	return nil
+}
+
+// reloadProviderConfig bind the raw config and command line flags to a new config of provider.
+func (h *Hub) reloadProviderConfig(pc *providerContext, raw interface{}) (interface{}, error) {
+	flags := pflag.NewFlagSet("reload", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.SetOutput(ioutil.Discard)
+	cfg, err := pc.bindConfig(raw, flags)
+	if err != nil || cfg == nil {
+		return cfg, err
+	}
+	if err := flags.Parse(h.args); err != nil && err != pflag.ErrHelp {
+		return nil, err
+	}
+	if errs := validateConfig(cfg); len(errs) > 0 {
+		return nil, errs
+	}
+	return cfg, nil
+}
+
+// providerConfig return the raw config of provider in config.
+func providerConfig(config map[string]interface{}, pc *providerContext) (interface{}, bool) {
+	if len(pc.key) > 0 {
+		if cfg, ok := config[pc.key]; ok {
+			return cfg, true
+		}
+		if providers, ok := config["providers"].(map[string]interface{}); ok {
+			cfg, ok := providers[pc.key]
+			return cfg, ok
+		}
+		return nil, false
+	}
+	if providers, ok := config["providers"].([]interface{}); ok {
+		for _, item := range providers {
+			if cfg, ok := item.(map[string]interface{}); ok && cfg["_name"] == pc.name {
+				return cfg, true
+			}
+		}
+	}
+	return nil, false
+}
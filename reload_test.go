@@ -0,0 +1,267 @@
+package servicehub
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type testReloadConfig struct {
+	Value string `file:"value" default:"default"`
+}
+
+type testReloadProvider struct {
+	Cfg    *testReloadConfig
+	refuse bool
+	values []string
+}
+
+func (p *testReloadProvider) Reload(cfg interface{}) error {
+	c := cfg.(*testReloadConfig)
+	if p.refuse && c.Value != "init" {
+		return fmt.Errorf("refused")
+	}
+	p.Cfg = c
+	p.values = append(p.values, c.Value)
+	return nil
+}
+
+func TestHub_Reload(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     map[string]interface{}
+		wantErr    bool
+		wantA      []string // values reloaded by reloader-a
+		wantStatic string   // value of static provider after reload
+	}{
+		{
+			name: "unchanged",
+			config: map[string]interface{}{
+				"reloader-a": map[string]interface{}{"value": "init"},
+				"reloader-b": map[string]interface{}{"value": "init"},
+				"static":     map[string]interface{}{"value": "init"},
+			},
+			wantStatic: "init",
+		},
+		{
+			name: "changed",
+			config: map[string]interface{}{
+				"reloader-a": map[string]interface{}{"value": "new"},
+				"reloader-b": map[string]interface{}{"value": "init"},
+				"static":     map[string]interface{}{"value": "init"},
+			},
+			wantA:      []string{"new"},
+			wantStatic: "init",
+		},
+		{
+			name: "refused",
+			config: map[string]interface{}{
+				"reloader-a": map[string]interface{}{"value": "new"},
+				"reloader-b": map[string]interface{}{"value": "new"},
+				"static":     map[string]interface{}{"value": "init"},
+			},
+			wantErr:    true,
+			wantA:      []string{"new", "init"},
+			wantStatic: "init",
+		},
+		{
+			name: "not reloader",
+			config: map[string]interface{}{
+				"reloader-a": map[string]interface{}{"value": "new"},
+				"reloader-b": map[string]interface{}{"value": "init"},
+				"static":     map[string]interface{}{"value": "new"},
+			},
+			wantErr:    true,
+			wantStatic: "init",
+		},
+		{
+			name: "unknown key changed",
+			config: map[string]interface{}{
+				"reloader-a": map[string]interface{}{"value": "init", "unknown": "new"},
+				"reloader-b": map[string]interface{}{"value": "init"},
+				"static":     map[string]interface{}{"value": "init"},
+			},
+			wantStatic: "init",
+		},
+		{
+			name: "meta key changed",
+			config: map[string]interface{}{
+				"reloader-a": map[string]interface{}{"value": "new", "_restart": "always"},
+				"reloader-b": map[string]interface{}{"value": "init"},
+				"static":     map[string]interface{}{"value": "init"},
+			},
+			wantErr:    true,
+			wantStatic: "init",
+		},
+		{
+			name: "provider added",
+			config: map[string]interface{}{
+				"reloader-a": map[string]interface{}{"value": "new"},
+				"reloader-b": map[string]interface{}{"value": "init"},
+				"static":     map[string]interface{}{"value": "init"},
+				"static@b":   map[string]interface{}{"value": "init"},
+			},
+			wantErr:    true,
+			wantStatic: "init",
+		},
+		{
+			name: "provider removed",
+			config: map[string]interface{}{
+				"reloader-a": map[string]interface{}{"value": "new"},
+				"reloader-b": map[string]interface{}{"value": "init"},
+			},
+			wantErr:    true,
+			wantStatic: "init",
+		},
+		{
+			name: "bindings changed",
+			config: map[string]interface{}{
+				"bindings":   map[string]interface{}{"a": "a@b"},
+				"reloader-a": map[string]interface{}{"value": "new"},
+				"reloader-b": map[string]interface{}{"value": "init"},
+				"static":     map[string]interface{}{"value": "init"},
+			},
+			wantErr:    true,
+			wantStatic: "init",
+		},
+		{
+			name: "config tag section changed",
+			config: map[string]interface{}{
+				"reloader-a": map[string]interface{}{"value": "new"},
+				"reloader-b": map[string]interface{}{"value": "init"},
+				"static": map[string]interface{}{
+					"value": "init",
+					"extra": map[string]interface{}{"key": "new"},
+				},
+			},
+			wantErr:    true,
+			wantStatic: "init",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := &testReloadProvider{}, &testReloadProvider{refuse: true}
+			static := &struct {
+				Cfg   *testReloadConfig
+				Extra map[string]interface{} `config:"extra"`
+			}{}
+			r := NewRegistry()
+			r.Register("reloader-a", &Spec{
+				ConfigFunc: func() interface{} { return &testReloadConfig{} },
+				Creator:    func() Provider { return a },
+			})
+			r.Register("reloader-b", &Spec{
+				Dependencies: []string{"a"},
+				Services:     []string{"b"},
+				ConfigFunc:   func() interface{} { return &testReloadConfig{} },
+				Creator:      func() Provider { return b },
+			})
+			r.Register("static", &Spec{
+				Services:   []string{"a"},
+				ConfigFunc: func() interface{} { return &testReloadConfig{} },
+				Creator:    func() Provider { return static },
+			})
+			hub := New(WithRegistry(r))
+			err := hub.Init(map[string]interface{}{
+				"reloader-a": map[string]interface{}{"value": "init"},
+				"reloader-b": map[string]interface{}{"value": "init"},
+				"static":     map[string]interface{}{"value": "init"},
+			}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+			if err != nil {
+				t.Fatalf("Hub.Init() = %v, want nil", err)
+			}
+			err = hub.Reload(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Hub.Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(a.values) != fmt.Sprint(tt.wantA) {
+				t.Errorf("reloader-a reloaded %v, want %v", a.values, tt.wantA)
+			}
+			if got := hub.providersMap["static"][0].cfg.(*testReloadConfig).Value; got != tt.wantStatic {
+				t.Errorf("config of static = %q, want %q", got, tt.wantStatic)
+			}
+			want := "init"
+			if !tt.wantErr && len(tt.wantA) > 0 {
+				want = tt.wantA[len(tt.wantA)-1]
+			}
+			if got := hub.providersMap["reloader-a"][0].cfg.(*testReloadConfig).Value; got != want {
+				t.Errorf("config of reloader-a = %q, want %q", got, want)
+			}
+			if raw := hub.providersMap["reloader-a"][0].rawCfg; !tt.wantErr && !reflect.DeepEqual(raw, tt.config["reloader-a"]) {
+				t.Errorf("raw config of reloader-a = %v, want %v", raw, tt.config["reloader-a"])
+			}
+		})
+	}
+}
+
+func TestHub_reloadFromLoader(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(file, []byte("reloader:\n    value: init\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := &testReloadProvider{}
+	r := NewRegistry()
+	r.Register("reloader", &Spec{
+		ConfigFunc: func() interface{} { return &testReloadConfig{} },
+		Creator:    func() Provider { return p },
+	})
+	hub := New(WithRegistry(r))
+	hub.configLoader = &configLoader{files: []string{file}}
+	cfg, _, err := hub.configLoader.load(hub)
+	if err != nil {
+		t.Fatalf("configLoader.load() = %v, want nil", err)
+	}
+	if err := hub.Init(cfg, pflag.NewFlagSet("test", pflag.ContinueOnError), nil); err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	stat := hub.configLoader.stat()
+	if err := os.WriteFile(file, []byte("reloader:\n    value: changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if hub.configLoader.stat() == stat {
+		t.Errorf("configLoader.stat() not changed after file changed")
+	}
+	if err := hub.reloadFromLoader(); err != nil {
+		t.Fatalf("Hub.reloadFromLoader() = %v, want nil", err)
+	}
+	if p.Cfg == nil || p.Cfg.Value != "changed" {
+		t.Errorf("reloader config = %v, want changed", p.Cfg)
+	}
+	if hub.ConfigSource("reloader.value") != file {
+		t.Errorf("Hub.ConfigSource() = %q, want %q", hub.ConfigSource("reloader.value"), file)
+	}
+}
+
+func TestHub_ReloadConcurrent(t *testing.T) {
+	r := NewRegistry()
+	r.Register("reloader", &Spec{
+		ConfigFunc: func() interface{} { return &testReloadConfig{} },
+		Creator:    func() Provider { return &testReloadProvider{} },
+	})
+	hub := New(WithRegistry(r))
+	if err := hub.Init(map[string]interface{}{"reloader": nil}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil); err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			hub.ProviderInfos()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		err := hub.Reload(map[string]interface{}{
+			"reloader": map[string]interface{}{"value": fmt.Sprint(i)},
+		})
+		if err != nil {
+			t.Fatalf("Hub.Reload() = %v, want nil", err)
+		}
+	}
+	wg.Wait()
+}
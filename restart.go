@@ -0,0 +1,177 @@
+package servicehub
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/recallsong/go-utils/config"
+)
+
+// RestartPolicy decides whether Run or a task of provider is restarted after it returns.
+type RestartPolicy string
+
+// restart policies
+const (
+	RestartNever     RestartPolicy = "never"
+	RestartOnFailure RestartPolicy = "on-failure"
+	RestartAlways    RestartPolicy = "always"
+)
+
+type restartOptions struct {
+	Policy     RestartPolicy `file:"policy"`
+	MaxRetries int           `file:"max_retries"` // zero means no limit
+	Backoff    time.Duration `file:"backoff"`
+	MaxBackoff time.Duration `file:"max_backoff"`
+	Jitter     float64       `file:"jitter"` // randomize the backoff by ±Jitter*backoff
+}
+
+// maxRestartBackoff limits the exponential backoff if MaxBackoff is zero.
+const maxRestartBackoff = time.Hour
+
+func defaultRestartOptions() restartOptions {
+	return restartOptions{
+		Policy:     RestartNever,
+		Backoff:    time.Second,
+		MaxBackoff: 30 * time.Second,
+	}
+}
+
+// parseRestartOptions parse the _restart config of provider, it can be a policy or a map.
+func parseRestartOptions(val interface{}) (restartOptions, error) {
+	opts := defaultRestartOptions()
+	switch v := val.(type) {
+	case string:
+		opts.Policy = RestartPolicy(v)
+	case map[string]interface{}:
+		err := config.ConvertData(v, &opts, "file")
+		if err != nil {
+			return opts, err
+		}
+	default:
+		return opts, fmt.Errorf("invalid type %T", val)
+	}
+	switch opts.Policy {
+	case RestartNever, RestartOnFailure, RestartAlways:
+	case "":
+		opts.Policy = RestartNever
+	default:
+		return opts, fmt.Errorf("unknown restart policy %q", opts.Policy)
+	}
+	return opts, opts.validate()
+}
+
+func (o *restartOptions) validate() error {
+	if o.Backoff <= 0 {
+		return fmt.Errorf("backoff must be positive")
+	}
+	if o.MaxBackoff < 0 {
+		return fmt.Errorf("max_backoff must not be negative")
+	}
+	if o.Jitter < 0 || o.Jitter > 1 {
+		return fmt.Errorf("jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+func (o *restartOptions) delay(retries int) time.Duration {
+	limit := o.MaxBackoff
+	if limit <= 0 {
+		limit = maxRestartBackoff
+	}
+	d := o.Backoff
+	for i := 0; i < retries && d < limit; i++ {
+		if d > limit/2 {
+			d = limit
+			break
+		}
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+	if o.Jitter > 0 {
+		j := time.Duration((rand.Float64()*2 - 1) * o.Jitter * float64(d))
+		if j > 0 && d > math.MaxInt64-j {
+			return math.MaxInt64
+		}
+		d += j
+	}
+	return d
+}
+
+// WithTaskRestart setup the restart policy of task.
+func WithTaskRestart(policy RestartPolicy) TaskOption {
+	return func(t *task) {
+		t.restart.Policy = policy
+	}
+}
+
+// WithTaskMaxRetries setup the max number of restarts of task, zero means no limit.
+func WithTaskMaxRetries(n int) TaskOption {
+	return func(t *task) {
+		t.restart.MaxRetries = n
+	}
+}
+
+// WithTaskBackoff setup the exponential backoff between restarts of task,
+// backoff must be positive, and zero maxBackoff means limited by one hour.
+func WithTaskBackoff(backoff, maxBackoff time.Duration) TaskOption {
+	return func(t *task) {
+		t.restart.Backoff = backoff
+		t.restart.MaxBackoff = maxBackoff
+	}
+}
+
+// WithTaskJitter randomize the backoff of task by ±jitter*backoff, jitter is between 0 and 1,
+// the task fails without running if it is out of range.
+func WithTaskJitter(jitter float64) TaskOption {
+	return func(t *task) {
+		t.restart.Jitter = jitter
+	}
+}
+
+// runWithRestart run fn and restart it according to the restart options until ctx is done.
+func (h *Hub) runWithRestart(ctx context.Context, name string, opts restartOptions, fn func(context.Context) error) error {
+	if opts.Policy != RestartNever && len(opts.Policy) > 0 {
+		if err := opts.validate(); err != nil {
+			return fmt.Errorf("invalid restart options of %s: %s", name, err)
+		}
+	}
+	for retries := 0; ; retries++ {
+		err := fn(ctx)
+		if ctx.Err() != nil {
+			return err
+		}
+		switch opts.Policy {
+		case RestartAlways:
+		case RestartOnFailure:
+			if err == nil {
+				return nil
+			}
+		default:
+			return err
+		}
+		if opts.MaxRetries > 0 && retries >= opts.MaxRetries {
+			if err != nil {
+				return fmt.Errorf("%s still failed after %d retries: %w", name, retries, err)
+			}
+			return nil
+		}
+		delay := opts.delay(retries)
+		if err != nil {
+			h.logger.Warnf("%s failed: %s, restart after %s", name, err, delay)
+		} else {
+			h.logger.Infof("%s exit, restart after %s", name, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
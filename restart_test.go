@@ -0,0 +1,178 @@
+package servicehub
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/recallsong/servicehub/logs/logrusx"
+)
+
+func Test_parseRestartOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     interface{}
+		want    restartOptions
+		wantErr bool
+	}{
+		{
+			name: "policy",
+			val:  "on-failure",
+			want: restartOptions{
+				Policy:     RestartOnFailure,
+				Backoff:    time.Second,
+				MaxBackoff: 30 * time.Second,
+			},
+		},
+		{
+			name: "map",
+			val: map[string]interface{}{
+				"policy":      "always",
+				"max_retries": 3,
+				"backoff":     "100ms",
+				"jitter":      0.5,
+			},
+			want: restartOptions{
+				Policy:     RestartAlways,
+				MaxRetries: 3,
+				Backoff:    100 * time.Millisecond,
+				MaxBackoff: 30 * time.Second,
+				Jitter:     0.5,
+			},
+		},
+		{
+			name:    "unknown policy",
+			val:     "sometimes",
+			wantErr: true,
+		},
+		{
+			name: "invalid jitter",
+			val: map[string]interface{}{
+				"policy": "always",
+				"jitter": 2,
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero backoff",
+			val: map[string]interface{}{
+				"policy":  "always",
+				"backoff": "0s",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative max backoff",
+			val: map[string]interface{}{
+				"policy":      "always",
+				"max_backoff": "-1s",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid type",
+			val:     1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRestartOptions(tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseRestartOptions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRestartOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_restartOptions_delay(t *testing.T) {
+	opts := restartOptions{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	for retries, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := opts.delay(retries); got != want {
+			t.Errorf("restartOptions.delay(%d) = %v, want %v", retries, got, want)
+		}
+	}
+	unlimited := restartOptions{Backoff: time.Second}
+	for _, retries := range []int{12, 63, 64, 1000} {
+		if got := unlimited.delay(retries); got != maxRestartBackoff {
+			t.Errorf("restartOptions.delay(%d) without max backoff = %v, want %v", retries, got, maxRestartBackoff)
+		}
+	}
+	large := restartOptions{Backoff: time.Duration(math.MaxInt64/2 + 1), MaxBackoff: math.MaxInt64}
+	if got := large.delay(2); got != math.MaxInt64 {
+		t.Errorf("restartOptions.delay(2) = %v, want %v", got, time.Duration(math.MaxInt64))
+	}
+}
+
+func TestHub_runWithRestart(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      restartOptions
+		results   []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "never",
+			opts:      restartOptions{Policy: RestartNever},
+			results:   []error{fmt.Errorf("error")},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "on failure",
+			opts:      restartOptions{Policy: RestartOnFailure, Backoff: time.Millisecond},
+			results:   []error{fmt.Errorf("error"), fmt.Errorf("error"), nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "always",
+			opts:      restartOptions{Policy: RestartAlways, Backoff: time.Millisecond, MaxRetries: 2},
+			results:   []error{nil, nil, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "max retries",
+			opts:      restartOptions{Policy: RestartOnFailure, Backoff: time.Millisecond, MaxRetries: 1},
+			results:   []error{fmt.Errorf("error"), fmt.Errorf("error")},
+			wantCalls: 2,
+			wantErr:   true,
+		},
+		{
+			name:      "invalid jitter",
+			opts:      restartOptions{Policy: RestartAlways, Backoff: time.Millisecond, Jitter: 2},
+			wantCalls: 0,
+			wantErr:   true,
+		},
+		{
+			name:      "zero backoff",
+			opts:      restartOptions{Policy: RestartOnFailure},
+			wantCalls: 0,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := &Hub{logger: logrusx.New()}
+			var calls int
+			err := hub.runWithRestart(context.Background(), "test", tt.opts, func(ctx context.Context) error {
+				err := tt.results[calls]
+				calls++
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Hub.runWithRestart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Hub.runWithRestart() calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package servicehub
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/recallsong/go-utils/errorx"
+)
+
+// Lifetime of the instances which a DependencyProvider provides
+type Lifetime string
+
+// lifetimes
+const (
+	// LifetimeDefault calls Provide on every lookup, it is the behavior when Lifetime is not declared
+	LifetimeDefault Lifetime = ""
+	// LifetimeSingleton calls Provide once, the instance is shared by all dependents
+	LifetimeSingleton Lifetime = "singleton"
+	// LifetimePerDependent calls Provide once for each dependent provider
+	LifetimePerDependent Lifetime = "per-dependent"
+	// LifetimeTransient calls Provide on every lookup, the instances looked up through a Scope are closed with it
+	LifetimeTransient Lifetime = "transient"
+	// LifetimeScoped calls Provide once for each Scope, the instance can be looked up only through a Scope
+	LifetimeScoped Lifetime = "scoped"
+)
+
+func (l Lifetime) valid() bool {
+	switch l {
+	case LifetimeDefault, LifetimeSingleton, LifetimePerDependent, LifetimeTransient, LifetimeScoped:
+		return true
+	}
+	return false
+}
+
+// ProviderLifetime .
+type ProviderLifetime interface {
+	Lifetime() Lifetime
+}
+
+// Scope is a unit of work such as an HTTP request, the scoped and transient instances
+// looked up through it are closed when the Scope is closed.
+type Scope struct {
+	context.Context
+	hub       *Hub
+	lock      sync.Mutex
+	instances map[*providerContext]interface{}
+	decorates map[decoratedKey]interface{}
+	closers   []io.Closer
+	closed    bool
+}
+
+// NewScope .
+func (h *Hub) NewScope(ctx context.Context) *Scope {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Scope{
+		Context:   ctx,
+		hub:       h,
+		instances: make(map[*providerContext]interface{}),
+		decorates: make(map[decoratedKey]interface{}),
+	}
+}
+
+// Hub .
+func (s *Scope) Hub() *Hub { return s.hub }
+
+// Service return the service in the Scope.
+func (s *Scope) Service(name string, options ...interface{}) interface{} {
+	dc := newDependencyContext(
+		name,
+		"",
+		"",
+		nil,
+		reflect.StructTag(""),
+	)
+	dc.scope = s
+	instance, err := s.hub.getService(dc, options...)
+	if err != nil {
+		s.hub.logger.Errorf("failed to get service %s: %s", name, err)
+	}
+	return instance
+}
+
+// Close close the instances of Scope in reverse order of creation.
+func (s *Scope) Close() error {
+	s.lock.Lock()
+	closers := s.closers
+	s.closers, s.instances, s.decorates, s.closed = nil, nil, nil, true
+	s.lock.Unlock()
+	var errs errorx.Errors
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.MaybeUnwrap()
+}
+
+// scoped return the instance of provider in the Scope, create it if not exist.
+// create is called without lock, because Provide may lookup other services through the Scope,
+// the instance is discarded and closed if another one is stored or the Scope is closed meanwhile.
+func (s *Scope) scoped(pc *providerContext, create func() interface{}) interface{} {
+	s.lock.Lock()
+	instance, ok := s.instances[pc]
+	closed := s.closed
+	s.lock.Unlock()
+	if closed {
+		return nil
+	} else if ok {
+		return instance
+	}
+	instance = create()
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		s.discard(instance, "scoped")
+		return nil
+	}
+	if exist, ok := s.instances[pc]; ok {
+		s.lock.Unlock()
+		s.discard(instance, "scoped")
+		return exist
+	}
+	s.instances[pc] = instance
+	s.track(instance)
+	s.lock.Unlock()
+	return instance
+}
+
+// decorated return the decorated scoped instance in the Scope, decorate it if not exist.
+// decorate is called without lock like create of scoped, the decorated instance is not closed if discarded,
+// because it wraps the scoped instance which is closed with the Scope.
+func (s *Scope) decorated(key decoratedKey, decorate func() interface{}) interface{} {
+	s.lock.Lock()
+	instance, ok := s.decorates[key]
+	closed := s.closed
+	s.lock.Unlock()
+	if closed {
+		return nil
+	} else if ok {
+		return instance
+	}
+	instance = decorate()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return nil
+	}
+	if exist, ok := s.decorates[key]; ok {
+		return exist
+	}
+	s.decorates[key] = instance
+	return instance
+}
+
+// transient add instance to be closed with the Scope, the instance is closed immediately if the Scope has been closed.
+func (s *Scope) transient(instance interface{}) interface{} {
+	s.lock.Lock()
+	closed := s.closed
+	if !closed {
+		s.track(instance)
+	}
+	s.lock.Unlock()
+	if closed {
+		s.discard(instance, "transient")
+		return nil
+	}
+	return instance
+}
+
+// discard close the instance which is not kept by the Scope.
+func (s *Scope) discard(instance interface{}, lifetime string) {
+	if closer, ok := instance.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			s.hub.logger.Errorf("failed to close discarded %s instance: %s", lifetime, err)
+		}
+	}
+}
+
+func (s *Scope) track(instance interface{}) {
+	if closer, ok := instance.(io.Closer); ok {
+		s.closers = append(s.closers, closer)
+	}
+}
+
+// provideWithLifetime return the instance of DependencyProvider according to its Lifetime.
+func (c *providerContext) provideWithLifetime(prod DependencyProvider, dc DependencyContext, options ...interface{}) interface{} {
+	var scope *Scope
+	if d, ok := dc.(*dependencyContext); ok {
+		scope = d.scope
+	}
+	switch c.lifetime {
+	case LifetimeSingleton:
+		c.instanceOnce.Do(func() {
+			c.instance = prod.Provide(dc, options...)
+		})
+		return c.instance
+	case LifetimePerDependent:
+		key := dependentKey(dc)
+		if instance, ok := c.instances.Load(key); ok {
+			return instance
+		}
+		instance, _ := c.instances.LoadOrStore(key, prod.Provide(dc, options...))
+		return instance
+	case LifetimeTransient:
+		instance := prod.Provide(dc, options...)
+		if scope != nil {
+			return scope.transient(instance)
+		}
+		return instance
+	case LifetimeScoped:
+		if scope == nil {
+			return nil
+		}
+		return scope.scoped(c, func() interface{} {
+			return prod.Provide(dc, options...)
+		})
+	}
+	return prod.Provide(dc, options...)
+}
+
+// dependentKey return the key of caller which per-dependent instances are keyed by.
+func dependentKey(dc DependencyContext) string {
+	if len(dc.CallerLabel()) > 0 {
+		return dc.Caller() + "@" + dc.CallerLabel()
+	}
+	return dc.Caller()
+}
+
+// checkLifetime check the provider can provide instances with its Lifetime.
+func (c *providerContext) checkLifetime() error {
+	if c.lifetime == LifetimeDefault {
+		return nil
+	}
+	if _, ok := c.provider.(DependencyProvider); !ok {
+		return fmt.Errorf("provider %s with lifetime %s must implement DependencyProvider", c.name, c.lifetime)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package servicehub
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type testLifetimeInstance struct {
+	id     int
+	closed bool
+}
+
+func (i *testLifetimeInstance) Close() error {
+	i.closed = true
+	return nil
+}
+
+type testLifetimeProvider struct {
+	count int
+	last  *testLifetimeInstance
+}
+
+func (p *testLifetimeProvider) Provide(ctx DependencyContext, options ...interface{}) interface{} {
+	p.count++
+	p.last = &testLifetimeInstance{id: p.count}
+	return p.last
+}
+
+func TestHub_Lifetime(t *testing.T) {
+	type consumer struct {
+		Instance *testLifetimeInstance `autowired:"instance"`
+	}
+	tests := []struct {
+		name      string
+		lifetime  Lifetime
+		sameHub   bool // two lookups from hub get the same instance
+		sameDeps  bool // two dependents get the same instance
+		sameScope bool // two lookups from scope get the same instance
+		closed    bool // instances of scope are closed with it
+		wantErr   bool
+	}{
+		{name: "default", lifetime: LifetimeDefault},
+		{name: "singleton", lifetime: LifetimeSingleton, sameHub: true, sameDeps: true, sameScope: true},
+		{name: "per-dependent", lifetime: LifetimePerDependent, sameHub: true, sameScope: true},
+		{name: "transient", lifetime: LifetimeTransient, closed: true},
+		{name: "scoped", lifetime: LifetimeScoped, sameScope: true, closed: true},
+		{name: "invalid", lifetime: Lifetime("invalid"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register("instance-provider", &Spec{
+				Services: []string{"instance"},
+				Lifetime: tt.lifetime,
+				Creator:  func() Provider { return &testLifetimeProvider{} },
+			})
+			c1, c2 := &consumer{}, &consumer{}
+			cfg := map[string]interface{}{"instance-provider": nil}
+			if tt.lifetime != LifetimeScoped {
+				r.Register("consumer1", &Spec{Creator: func() Provider { return c1 }})
+				r.Register("consumer2", &Spec{Creator: func() Provider { return c2 }})
+				cfg["consumer1"], cfg["consumer2"] = nil, nil
+			}
+			hub := New(WithRegistry(r))
+			err := hub.Init(cfg, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Hub.Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.lifetime != LifetimeScoped {
+				if (c1.Instance == c2.Instance) != tt.sameDeps {
+					t.Errorf("dependents got same instance = %v, want %v", c1.Instance == c2.Instance, tt.sameDeps)
+				}
+				if same := hub.Service("instance") == hub.Service("instance"); same != tt.sameHub {
+					t.Errorf("Hub.Service() got same instance = %v, want %v", same, tt.sameHub)
+				}
+			} else if hub.Service("instance") != nil {
+				t.Errorf("Hub.Service() got scoped instance out of Scope")
+			}
+
+			scope := hub.NewScope(context.Background())
+			i1 := scope.Service("instance").(*testLifetimeInstance)
+			i2 := scope.Service("instance").(*testLifetimeInstance)
+			if (i1 == i2) != tt.sameScope {
+				t.Errorf("Scope.Service() got same instance = %v, want %v", i1 == i2, tt.sameScope)
+			}
+			if tt.lifetime == LifetimeScoped {
+				other := hub.NewScope(context.Background())
+				if other.Service("instance") == i1 {
+					t.Errorf("Scope.Service() got same instance in different scopes")
+				}
+			}
+			if err := scope.Close(); err != nil {
+				t.Errorf("Scope.Close() = %v, want nil", err)
+			}
+			if i1.closed != tt.closed || i2.closed != tt.closed {
+				t.Errorf("instances closed = %v, %v, want %v", i1.closed, i2.closed, tt.closed)
+			}
+		})
+	}
+}
+
+func TestHub_ScopedDependency(t *testing.T) {
+	type consumer struct {
+		Instance *testLifetimeInstance `autowired:"instance"`
+	}
+	r := NewRegistry()
+	r.Register("instance-provider", &Spec{
+		Services: []string{"instance"},
+		Lifetime: LifetimeScoped,
+		Creator:  func() Provider { return &testLifetimeProvider{} },
+	})
+	r.Register("consumer", &Spec{Creator: func() Provider { return &consumer{} }})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"instance-provider": nil,
+		"consumer":          nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err == nil {
+		t.Fatalf("Hub.Init() = nil, want error of injecting scoped provider")
+	}
+}
+
+func TestScope_ClosedTransient(t *testing.T) {
+	p := &testLifetimeProvider{}
+	r := NewRegistry()
+	r.Register("instance-provider", &Spec{
+		Services: []string{"instance"},
+		Lifetime: LifetimeTransient,
+		Creator:  func() Provider { return p },
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{"instance-provider": nil}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	scope := hub.NewScope(context.Background())
+	if err := scope.Close(); err != nil {
+		t.Fatalf("Scope.Close() = %v, want nil", err)
+	}
+	if instance := scope.Service("instance"); instance != nil {
+		t.Errorf("Scope.Service() after Close = %v, want nil", instance)
+	}
+	if p.count != 1 || !p.last.closed {
+		t.Errorf("instance provided after Scope closed is not closed")
+	}
+	if len(scope.closers) > 0 {
+		t.Errorf("closed Scope tracks %d instances, want none", len(scope.closers))
+	}
+}
+
+type testFuncProvider func(ctx DependencyContext) interface{}
+
+func (p testFuncProvider) Provide(ctx DependencyContext, options ...interface{}) interface{} {
+	return p(ctx)
+}
+
+func TestScope_ScopedLookup(t *testing.T) {
+	var scope *Scope
+	r := NewRegistry()
+	r.Register("instance-provider", &Spec{
+		Services: []string{"instance"},
+		Lifetime: LifetimeScoped,
+		Creator:  func() Provider { return &testLifetimeProvider{} },
+	})
+	r.Register("wrapper-provider", &Spec{
+		Services: []string{"wrapper"},
+		Lifetime: LifetimeScoped,
+		Creator: func() Provider {
+			return testFuncProvider(func(ctx DependencyContext) interface{} {
+				return []interface{}{scope.Service("instance")}
+			})
+		},
+	})
+	r.RegisterDecorator("wrapper", func(instance interface{}, dc DependencyContext) interface{} {
+		return append(instance.([]interface{}), scope.Service("instance"))
+	})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"instance-provider": nil,
+		"wrapper-provider":  nil,
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Hub.Init() = %v, want nil", err)
+	}
+	if _, err := hub.getService(newDependencyContext("instance", "", "", nil, "")); err == nil {
+		t.Errorf("Hub.getService() of scoped provider out of Scope = nil, want error")
+	}
+	scope = hub.NewScope(context.Background())
+	defer scope.Close()
+	wrapper := scope.Service("wrapper").([]interface{})
+	instance := scope.Service("instance")
+	if len(wrapper) != 2 || wrapper[0] != instance || wrapper[1] != instance {
+		t.Errorf("Scope.Service() = %v, want scoped instance %v looked up in Provide and decorator", wrapper, instance)
+	}
+}
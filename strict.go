@@ -0,0 +1,164 @@
+package servicehub
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/recallsong/go-utils/config"
+	"github.com/recallsong/go-utils/errorx"
+)
+
+// knownMetaKeys are the keys starting with _ in provider config which are handled by hub
+var knownMetaKeys = map[string]bool{
+	"_name":         true,
+	"_enable":       true,
+	"_exit_timeout": true,
+	"_restart":      true,
+	"_bindings":     true,
+}
+
+// checkUnknownConfig report the config keys which are not mapped to any field of provider config,
+// and warn the unknown meta keys.
+func (h *Hub) checkUnknownConfig() error {
+	var errs errorx.Errors
+	for _, pc := range h.providers {
+		raw, ok := pc.rawCfg.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key := pc.key
+		if len(key) <= 0 {
+			key = pc.name
+		}
+		data := make(map[string]interface{}, len(raw))
+		for k, v := range raw {
+			if strings.HasPrefix(k, "_") {
+				if !knownMetaKeys[k] {
+					h.logger.Warnf("unknown meta key %s in config of provider %s", k, key)
+				}
+				continue
+			}
+			data[k] = v
+		}
+		if len(data) <= 0 {
+			continue
+		}
+		if unknown := pc.unknownConfigKeys(data); len(unknown) > 0 {
+			errs.Append(fmt.Errorf("provider %s: unknown config keys: %s", key, strings.Join(unknown, ", ")))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("strict config: %w", errs)
+	}
+	return nil
+}
+
+// unknownConfigKeys return the keys of data which are not mapped to any field with file tag in config of provider,
+// nor read by any field with config tag of provider.
+func (c *providerContext) unknownConfigKeys(data map[string]interface{}) []string {
+	known := make(map[string]interface{})
+	if creator, ok := c.define.(ConfigCreator); ok {
+		if cfg := creator.Config(); cfg != nil {
+			known = knownConfigKeys(cfg)
+		}
+	}
+	if c.structType != nil {
+		for i, num := 0, c.structType.NumField(); i < num; i++ {
+			path, ok := c.structType.Field(i).Tag.Lookup("config")
+			if !ok || strings.HasPrefix(path, rootConfigPrefix) {
+				continue
+			}
+			known[strings.SplitN(path, ".", 2)[0]] = nil // any keys in section
+		}
+	}
+	var unknown []string
+	collectUnknownKeys(known, data, "", &unknown)
+	sort.Strings(unknown)
+	return unknown
+}
+
+// knownConfigKeys convert cfg to map in the same way as config is decoded,
+// so that the keys of map are exactly the keys which can be decoded into cfg.
+// The nil pointers to struct in cfg are allocated to convert their fields into map.
+func knownConfigKeys(cfg interface{}) map[string]interface{} {
+	allocStructPointers(reflect.ValueOf(cfg), make(map[reflect.Type]bool))
+	return structConfigKeys(cfg)
+}
+
+// structConfigKeys convert cfg to map, the pointers to struct, which are kept as values by conversion, are converted too.
+func structConfigKeys(cfg interface{}) map[string]interface{} {
+	known := make(map[string]interface{})
+	config.ConvertData(cfg, &known, "file")
+	expandStructPointers(known)
+	return known
+}
+
+func expandStructPointers(known map[string]interface{}) {
+	for key, val := range known {
+		if sub, ok := val.(map[string]interface{}); ok {
+			expandStructPointers(sub)
+			continue
+		}
+		v := reflect.ValueOf(val)
+		if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+			known[key] = structConfigKeys(val)
+		}
+	}
+}
+
+// allocStructPointers set the nil pointers to struct of exported fields to new values recursively,
+// the types in path are not allocated again to stop at recursive types.
+func allocStructPointers(value reflect.Value, path map[reflect.Type]bool) {
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct || path[value.Type()] {
+		return
+	}
+	typ := value.Type()
+	path[typ] = true
+	defer delete(path, typ)
+	for i, num := 0, typ.NumField(); i < num; i++ {
+		field := value.Field(i)
+		if len(typ.Field(i).PkgPath) > 0 || !field.CanSet() {
+			continue // unexported
+		}
+		if field.Kind() == reflect.Ptr && field.IsNil() && field.Type().Elem().Kind() == reflect.Struct {
+			if path[field.Type().Elem()] {
+				continue
+			}
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		allocStructPointers(field, path)
+	}
+}
+
+// collectUnknownKeys append the keys of data absent in known, keys are matched case-insensitively as decoding,
+// and the sections of known which are not struct, such as maps, accept any keys.
+func collectUnknownKeys(known, data map[string]interface{}, prefix string, unknown *[]string) {
+	for key, val := range data {
+		var (
+			sub   interface{}
+			found bool
+		)
+		for k, v := range known {
+			if strings.EqualFold(k, key) {
+				sub, found = v, true
+				break
+			}
+		}
+		if !found {
+			*unknown = append(*unknown, prefix+key)
+			continue
+		}
+		ks, ok := sub.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ds, ok := val.(map[string]interface{}); ok {
+			collectUnknownKeys(ks, ds, prefix+key+".", unknown)
+		}
+	}
+}
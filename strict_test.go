@@ -0,0 +1,133 @@
+package servicehub
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestHub_StrictConfig(t *testing.T) {
+	type tlsConfig struct {
+		Cert string `file:"cert"`
+	}
+	type idleConfig struct {
+		Max int `file:"max"`
+	}
+	type poolConfig struct {
+		Size int         `file:"size"`
+		Idle *idleConfig `file:"idle"`
+	}
+	type config struct {
+		Addr   string            `file:"addr"`
+		TLS    tlsConfig         `file:"tls"`
+		Pool   *poolConfig       `file:"pool"`
+		Labels map[string]string `file:"labels"`
+	}
+	type provider struct {
+		DB    map[string]interface{} `config:"db.master"`
+		Cache map[string]interface{} `config:"$._shared.cache"`
+	}
+	tests := []struct {
+		name    string
+		options []interface{}
+		args    []string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "not strict",
+			config: map[string]interface{}{
+				"test-provider": map[string]interface{}{"adress": ":8080"},
+			},
+		},
+		{
+			name:    "valid",
+			options: []interface{}{WithStrictConfig()},
+			config: map[string]interface{}{
+				"test-provider": map[string]interface{}{
+					"Addr":     ":8080",
+					"tls":      map[string]interface{}{"cert": "cert.pem"},
+					"pool":     map[string]interface{}{"size": 1, "idle": map[string]interface{}{"max": 2}},
+					"labels":   map[string]interface{}{"any": "value"},
+					"db":       map[string]interface{}{"master": map[string]interface{}{"host": "localhost"}},
+					"_enable":  true,
+					"_unknown": "only warned",
+				},
+				"no-config-provider": nil,
+			},
+		},
+		{
+			name:    "unknown keys",
+			options: []interface{}{WithStrictConfig()},
+			config: map[string]interface{}{
+				"test-provider": map[string]interface{}{
+					"adress": ":8080",
+					"tls":    map[string]interface{}{"crt": "cert.pem"},
+					"pool":   map[string]interface{}{"sise": 1, "idle": map[string]interface{}{"min": 1}},
+				},
+			},
+			wantErr: "provider test-provider: unknown config keys: adress, pool.idle.min, pool.sise, tls.crt",
+		},
+		{
+			name:    "top-level config tag",
+			options: []interface{}{WithStrictConfig()},
+			config: map[string]interface{}{
+				"test-provider": map[string]interface{}{"cache": nil},
+			},
+			wantErr: "provider test-provider: unknown config keys: cache",
+		},
+		{
+			name: "flag",
+			args: []string{"--config.strict"},
+			config: map[string]interface{}{
+				"no-config-provider": map[string]interface{}{"addr": ":8080"},
+			},
+			wantErr: "provider no-config-provider: unknown config keys: addr",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register("test-provider", &Spec{
+				ConfigFunc: func() interface{} { return &config{} },
+				Creator:    func() Provider { return &provider{} },
+			})
+			r.Register("no-config-provider", &Spec{
+				Creator: func() Provider { return "test" },
+			})
+			hub := New(append(tt.options, WithRegistry(r))...)
+			err := hub.Init(tt.config, pflag.NewFlagSet("test", pflag.ContinueOnError), tt.args)
+			if len(tt.wantErr) <= 0 {
+				if err != nil {
+					t.Fatalf("Hub.Init() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Hub.Init() = %v, want error contains %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_knownConfigKeys(t *testing.T) {
+	type node struct {
+		Name string `file:"name"`
+		Next *node  `file:"next"`
+	}
+	type config struct {
+		Root *node `file:"root"`
+	}
+	known := knownConfigKeys(&config{})
+	root, ok := known["root"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("knownConfigKeys() = %v, want keys of pointer to struct", known)
+	}
+	if _, ok := root["name"]; !ok {
+		t.Errorf("knownConfigKeys() root = %v, want name", root)
+	}
+	if next, ok := root["next"].(*node); !ok || next != nil {
+		t.Errorf("knownConfigKeys() root.next = %v, want nil of recursive type", next)
+	}
+}
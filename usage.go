@@ -7,18 +7,23 @@ import (
 
 // Usage .
 func Usage(names ...string) string {
+	return defaultRegistry.Usage(names...)
+}
+
+// Usage .
+func (r *Registry) Usage(names ...string) string {
 	buf := &bytes.Buffer{}
 	buf.WriteString("Service Providers:\n")
 	if len(names) <= 0 {
-		for name, define := range serviceProviders {
+		names = r.Names()
+	}
+	for _, name := range names {
+		r.lock.RLock()
+		define, ok := r.providers[name]
+		r.lock.RUnlock()
+		if ok {
 			providerUsage(name, define, buf)
 		}
-	} else {
-		for _, name := range names {
-			if define, ok := serviceProviders[name]; ok {
-				providerUsage(name, define, buf)
-			}
-		}
 	}
 	return buf.String()
 }
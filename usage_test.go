@@ -96,7 +96,7 @@ test2-provider
 				t.Errorf("Usage() = %v, want %v", got, tt.want)
 			}
 			for _, p := range tt.args.providers {
-				delete(serviceProviders, p.name)
+				defaultRegistry.Unregister(p.name)
 			}
 		})
 	}
@@ -0,0 +1,253 @@
+package servicehub
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/recallsong/go-utils/errorx"
+)
+
+// ConfigValidator is implemented by config which validates itself after default values, config, env and flags are bound.
+type ConfigValidator interface {
+	Validate() error
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// validateConfig check the validate tags of config fields and call Validate of config,
+// the rules of validate tag are separated by comma, such as `validate:"required,min=1,max=65535"`:
+//
+//	required         value must not be zero
+//	min=n, max=n     number or duration range, or length range of string, slice and map
+//	oneof=a b c      value must be one of the words
+//	regexp=pattern   string must match the pattern, it must be the last rule
+//
+// Zero values are checked by required, min and max, but skipped by oneof and regexp,
+// so that min=1 rejects an unset number, while an unset string may be empty. Unknown rules are reported for any value.
+func validateConfig(cfg interface{}) (errs errorx.Errors) {
+	if cfg == nil {
+		return nil
+	}
+	validateValue(reflect.ValueOf(cfg), "", &errs)
+	return errs
+}
+
+// validateConfigs validate the config and the config sections injected by config tag of all providers,
+// and report all violations together.
+func (h *Hub) validateConfigs() error {
+	var errs errorx.Errors
+	for _, pc := range h.providers {
+		for _, err := range validateConfig(pc.cfg) {
+			errs.Append(fmt.Errorf("provider %s: %s", pc.key, err))
+		}
+		for _, err := range pc.validateConfigSections() {
+			errs.Append(fmt.Errorf("provider %s: %s", pc.key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errs)
+	}
+	return nil
+}
+
+// validateConfigSections decode and validate the config sections of fields with config tag,
+// the validate tag of field applies to the whole section.
+func (c *providerContext) validateConfigSections() (errs errorx.Errors) {
+	if c.structType == nil {
+		return nil
+	}
+	for i, num := 0, c.structType.NumField(); i < num; i++ {
+		field := c.structType.Field(i)
+		path, ok := field.Tag.Lookup("config")
+		if !ok {
+			continue
+		}
+		val, err := c.decodeConfigSection(field.Type, path)
+		if err != nil {
+			errs.Append(withConfigPath(path, err))
+			continue
+		}
+		if rules, ok := field.Tag.Lookup("validate"); ok {
+			for _, err := range validateRules(val, rules) {
+				errs.Append(withConfigPath(path, err))
+			}
+		}
+		validateValue(val, path, &errs)
+	}
+	return errs
+}
+
+func validateValue(value reflect.Value, path string, errs *errorx.Errors) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+	validateFields(value, path, errs)
+	v := value.Interface()
+	if value.CanAddr() {
+		v = value.Addr().Interface()
+	}
+	if validator, ok := v.(ConfigValidator); ok {
+		if err := validator.Validate(); err != nil {
+			errs.Append(withConfigPath(path, err))
+		}
+	}
+}
+
+func validateFields(value reflect.Value, path string, errs *errorx.Errors) {
+	typ := value.Type()
+	for i, num := 0, typ.NumField(); i < num; i++ {
+		field := typ.Field(i)
+		if len(field.PkgPath) > 0 {
+			continue // unexported
+		}
+		name := strings.Split(field.Tag.Get("file"), ",")[0]
+		if len(name) <= 0 {
+			name = field.Name
+		}
+		fpath := joinConfigPath(path, name)
+		fval := value.Field(i)
+		if rules, ok := field.Tag.Lookup("validate"); ok {
+			for _, err := range validateRules(fval, rules) {
+				errs.Append(withConfigPath(fpath, err))
+			}
+		}
+		if field.Type != durationType {
+			validateValue(fval, fpath, errs)
+		}
+	}
+}
+
+func withConfigPath(path string, err error) error {
+	if len(path) <= 0 {
+		return err
+	}
+	return fmt.Errorf("%s: %s", path, err)
+}
+
+func validateRules(value reflect.Value, rules string) (errs []error) {
+	for len(rules) > 0 {
+		var rule string
+		if strings.HasPrefix(rules, "regexp=") {
+			rule, rules = rules, ""
+		} else if idx := strings.Index(rules, ","); idx >= 0 {
+			rule, rules = rules[:idx], rules[idx+1:]
+		} else {
+			rule, rules = rules, ""
+		}
+		name, arg := rule, ""
+		if idx := strings.Index(rule, "="); idx >= 0 {
+			name, arg = rule[:idx], rule[idx+1:]
+		}
+		switch name {
+		case "required":
+			if value.IsZero() {
+				errs = append(errs, fmt.Errorf("is required"))
+			}
+			continue
+		case "min", "max", "oneof", "regexp":
+		default:
+			errs = append(errs, fmt.Errorf("unknown validate rule %q", name))
+			continue
+		}
+		if (value.Kind() == reflect.Ptr && value.IsNil()) || (value.IsZero() && name != "min" && name != "max") {
+			continue
+		}
+		if err := validateRule(value, name, arg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func validateRule(value reflect.Value, name, arg string) error {
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	switch name {
+	case "min", "max":
+		cmp, err := compareValue(value, arg)
+		if err != nil {
+			return fmt.Errorf("invalid %s rule: %s", name, err)
+		}
+		if name == "min" && cmp < 0 {
+			return fmt.Errorf("%s must be at least %s", describeValue(value), arg)
+		} else if name == "max" && cmp > 0 {
+			return fmt.Errorf("%s must be at most %s", describeValue(value), arg)
+		}
+	case "oneof":
+		text := fmt.Sprint(value.Interface())
+		for _, item := range strings.Fields(arg) {
+			if item == text {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q must be one of [%s]", text, arg)
+	case "regexp":
+		if value.Kind() != reflect.String {
+			return fmt.Errorf("regexp rule is not supported for %s", value.Type())
+		}
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return fmt.Errorf("invalid regexp rule: %s", err)
+		}
+		if !re.MatchString(value.String()) {
+			return fmt.Errorf("%q must match %s", value.String(), arg)
+		}
+	}
+	return nil
+}
+
+// compareValue compare the value, or length of value, with arg.
+func compareValue(value reflect.Value, arg string) (int, error) {
+	if value.Type() == durationType {
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return 0, err
+		}
+		return compareFloat(float64(value.Int()), float64(d)), nil
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareFloat(value.Convert(reflect.TypeOf(float64(0))).Float(), n), nil
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return 0, err
+		}
+		return compareFloat(float64(value.Len()), float64(n)), nil
+	}
+	return 0, fmt.Errorf("not supported for %s", value.Type())
+}
+
+func compareFloat(a, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func describeValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return fmt.Sprintf("length %d", value.Len())
+	}
+	return fmt.Sprint(value.Interface())
+}
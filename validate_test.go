@@ -0,0 +1,166 @@
+package servicehub
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type testValidateConfig struct {
+	URL     string        `file:"url" validate:"required,regexp=^https?://"`
+	Port    int           `file:"port" validate:"min=1,max=65535"`
+	Mode    string        `file:"mode" validate:"oneof=dev prod"`
+	Timeout time.Duration `file:"timeout" validate:"min=1s,max=1m"`
+	Hosts   []string      `file:"hosts" validate:"max=2"`
+	TLS     *struct {
+		Cert string `file:"cert" validate:"required"`
+	} `file:"tls"`
+}
+
+func (c *testValidateConfig) Validate() error {
+	if c.Mode == "prod" && c.TLS == nil {
+		return errors.New("tls is required in prod mode")
+	}
+	return nil
+}
+
+func Test_validateConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  interface{}
+		want []string
+	}{
+		{
+			name: "valid",
+			cfg:  &testValidateConfig{URL: "http://localhost", Port: 80, Mode: "dev", Timeout: time.Second},
+		},
+		{
+			name: "zero values",
+			cfg:  &testValidateConfig{},
+			want: []string{"url: is required", "port: 0 must be at least 1", "timeout: 0s must be at least 1s"},
+		},
+		{
+			name: "invalid",
+			cfg: &testValidateConfig{
+				URL:     "localhost",
+				Port:    -1,
+				Mode:    "prod",
+				Timeout: time.Hour,
+				Hosts:   []string{"a", "b", "c"},
+			},
+			want: []string{
+				`url: "localhost" must match ^https?://`,
+				"port: -1 must be at least 1",
+				"timeout: 1h0m0s must be at most 1m",
+				"hosts: length 3 must be at most 2",
+				"tls is required in prod mode",
+			},
+		},
+		{
+			name: "nested",
+			cfg: &testValidateConfig{URL: "https://localhost", Port: 443, Mode: "test", Timeout: time.Second, TLS: &struct {
+				Cert string `file:"cert" validate:"required"`
+			}{}},
+			want: []string{`mode: "test" must be one of [dev prod]`, "tls.cert: is required"},
+		},
+		{
+			name: "unknown rule",
+			cfg: &struct {
+				Value string `validate:"unknown"`
+			}{"value"},
+			want: []string{`Value: unknown validate rule "unknown"`},
+		},
+		{
+			name: "unknown rule of zero value",
+			cfg: &struct {
+				Value string `validate:"unknown"`
+			}{},
+			want: []string{`Value: unknown validate rule "unknown"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range validateConfig(tt.cfg) {
+				got = append(got, err.Error())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("validateConfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHub_ValidateConfig(t *testing.T) {
+	r := NewRegistry()
+	initialized := false
+	for _, name := range []string{"provider-a", "provider-b"} {
+		r.Register(name, &Spec{
+			ConfigFunc: func() interface{} { return &testValidateConfig{} },
+			Creator: func() Provider {
+				return &testInitFuncProvider{func(ctx Context) error {
+					initialized = true
+					return nil
+				}}
+			},
+		})
+	}
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"provider-a": map[string]interface{}{"port": -1},
+		"provider-b": map[string]interface{}{"url": "http://localhost", "port": 70000},
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err == nil {
+		t.Fatalf("Hub.Init() = nil, want error of invalid config")
+	}
+	for _, want := range []string{"provider provider-a: url: is required", "provider provider-a: port: -1", "provider provider-b: port: 70000"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Hub.Init() = %v, want contains %q", err, want)
+		}
+	}
+	if initialized {
+		t.Errorf("provider initialized, want no provider initialized with invalid config")
+	}
+}
+
+func TestHub_ValidateConfigSections(t *testing.T) {
+	type dbConfig struct {
+		Host string `file:"host" validate:"required"`
+		Port int    `file:"port" default:"3306" validate:"min=1,max=65535"`
+	}
+	type consumer struct {
+		Master dbConfig  `config:"db.master"`
+		Slave  *dbConfig `config:"db.slave"`
+		Tags   []string  `config:"tags" validate:"min=1"`
+	}
+	r := NewRegistry()
+	r.Register("consumer", &Spec{Creator: func() Provider { return &consumer{} }})
+	hub := New(WithRegistry(r))
+	err := hub.Init(map[string]interface{}{
+		"consumer": map[string]interface{}{
+			"db": map[string]interface{}{
+				"master": map[string]interface{}{"port": 70000},
+				"slave":  map[string]interface{}{"host": "slave"},
+			},
+		},
+	}, pflag.NewFlagSet("test", pflag.ContinueOnError), nil)
+	if err == nil {
+		t.Fatalf("Hub.Init() = nil, want error of invalid config")
+	}
+	for _, want := range []string{
+		"provider consumer: db.master.host: is required",
+		"provider consumer: db.master.port: 70000 must be at most 65535",
+		"provider consumer: tags: length 0 must be at least 1",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Hub.Init() = %v, want contains %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "db.slave") {
+		t.Errorf("Hub.Init() = %v, want db.slave valid", err)
+	}
+}